@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clientOptions configure the HTTP client used to talk to Jenkins
+type clientOptions struct {
+	// cookies in NAME=VALUE format
+	cookies    []string
+	cookieFile string
+	// proxy overrides the proxy from the HTTP_PROXY/HTTPS_PROXY environment variables
+	proxy string
+	// maxIdleConns is the number of connections to Jenkins kept open for reuse
+	maxIdleConns int
+	// idleTimeout closes connections which weren't reused for this long
+	idleTimeout time.Duration
+	// http2 can be disabled for proxies which break it
+	http2 bool
+	// cache revalidates repeated API requests with their ETag or Last-Modified header
+	cache bool
+}
+
+// newHTTPClient creates the HTTP client used to talk to Jenkins.
+// Cookies from the command line and from a cookie file are added to its jar
+// so an existing browser (SSO) session can be reused.
+func newHTTPClient(jenkinsURL string, options clientOptions) (*http.Client, error) {
+	base, err := url.Parse(jenkinsURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JENKINS_URL %v: %v", jenkinsURL, err)
+	}
+
+	// The default transport has the dial and TLS handshake timeouts, so only the pool is changed.
+	// Commands querying many jobs at once would open a new connection for most requests
+	// with the default of two idle connections per host.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = options.maxIdleConns
+	transport.MaxIdleConnsPerHost = options.maxIdleConns
+	transport.IdleConnTimeout = options.idleTimeout
+	transport.ForceAttemptHTTP2 = options.http2
+	if !options.http2 {
+		// A non-nil empty map disables HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if options.proxy != "" {
+		proxyURL, err := url.Parse(options.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %v: %v", options.proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jenkinsCookies []*http.Cookie
+	if options.cookieFile != "" {
+		fileCookies, err := readCookieFile(options.cookieFile, base)
+		if err != nil {
+			return nil, err
+		}
+		jenkinsCookies = append(jenkinsCookies, fileCookies...)
+	}
+	for _, cookie := range options.cookies {
+		parts := strings.SplitN(cookie, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid cookie %q, expected NAME=VALUE", cookie)
+		}
+		jenkinsCookies = append(jenkinsCookies, &http.Cookie{Name: parts[0], Value: parts[1]})
+	}
+	jar.SetCookies(base, jenkinsCookies)
+
+	client := &http.Client{Jar: jar, Transport: transport}
+	if options.cache {
+		client.Transport = newCachingTransport(transport)
+	}
+	return client, nil
+}
+
+// readCookieFile reads cookies in the Netscape cookies.txt format
+// as exported by curl and most browser extensions.
+// A browser export has cookies for every site, so only the ones for the Jenkins host are kept,
+// with their path and secure flag, to not hand the session cookies of other sites to Jenkins.
+func readCookieFile(path string, base *url.URL) ([]*http.Cookie, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// domain, include subdomains, path, secure, expiry, name, value
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid line in cookie file %v: %q", path, line)
+		}
+		if !cookieDomainMatches(fields[0], fields[1] == "TRUE", base.Hostname()) {
+			continue
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry in cookie file %v: %q", path, line)
+		}
+		// 0 is a session cookie
+		if expiry != 0 && time.Unix(expiry, 0).Before(time.Now()) {
+			continue
+		}
+		cookie := &http.Cookie{Name: fields[5], Value: fields[6], Path: fields[2], Secure: fields[3] == "TRUE"}
+		if expiry != 0 {
+			cookie.Expires = time.Unix(expiry, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, scanner.Err()
+}
+
+// cookieDomainMatches tells if a cookie of the domain in a cookie file is sent to the host.
+// Subdomains only get the cookie if the include subdomains flag is set.
+func cookieDomainMatches(domain string, includeSubdomains bool, host string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	host = strings.ToLower(host)
+	return host == domain || (includeSubdomains && strings.HasSuffix(host, "."+domain))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookieFile(t *testing.T) {
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+	lines := []string{
+		"# Netscape HTTP Cookie File",
+		"jenkins.example.com\tFALSE\t/\tFALSE\t0\tsession\tjenkins",
+		"#HttpOnly_.example.com\tTRUE\t/\tFALSE\t" + fmt.Sprint(future) + "\tsso\tdomain",
+		"example.com\tFALSE\t/\tFALSE\t0\tparent\thost-only",
+		"other.example.org\tFALSE\t/\tFALSE\t0\tother\tsite",
+		"jenkins.example.com\tFALSE\t/\tTRUE\t0\tsecure\thttps-only",
+		"jenkins.example.com\tFALSE\t/job\tFALSE\t0\tjob\tjob-path",
+		"jenkins.example.com\tFALSE\t/\tFALSE\t" + fmt.Sprint(past) + "\texpired\told",
+	}
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := newHTTPClient("http://jenkins.example.com", clientOptions{cookieFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://jenkins.example.com/api/json", "session sso"},
+		{"https://jenkins.example.com/api/json", "secure session sso"},
+		{"http://jenkins.example.com/job/web/api/json", "job session sso"},
+		{"http://other.example.org/", ""},
+		{"http://example.com/", ""},
+	}
+	for _, test := range tests {
+		u, _ := url.Parse(test.url)
+		var names []string
+		for _, cookie := range client.Jar.Cookies(u) {
+			names = append(names, cookie.Name)
+		}
+		sort.Strings(names)
+		if got := strings.Join(names, " "); got != test.want {
+			t.Errorf("cookies for %v = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestCookieFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := ioutil.WriteFile(path, []byte("jenkins.example.com\tFALSE\t/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCookieFile(path, &url.URL{Host: "jenkins.example.com"}); err == nil {
+		t.Error("expected an error for a line with missing fields")
+	}
+}
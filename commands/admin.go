@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bndr/gojenkins"
+)
+
+// adminAction is an instance wide operation
+type adminAction struct {
+	endpoint string
+	message  string
+	// confirm makes the action require an explicit confirmation
+	confirm bool
+}
+
+var adminActions = map[string]adminAction{
+	"safe-restart":      {"/safeRestart", "Jenkins will restart once all running builds are finished", true},
+	"quiet-down":        {"/quietDown", "Jenkins is quieting down, no new builds will be started", false},
+	"cancel-quiet-down": {"/cancelQuietDown", "Jenkins is no longer quieting down", false},
+}
+
+type Admin struct {
+	jenkins   *gojenkins.Jenkins
+	action    string
+	confirmed bool
+}
+
+func NewAdmin(jenkins *gojenkins.Jenkins, action string, confirmed bool) *Admin {
+	return &Admin{jenkins, action, confirmed}
+}
+
+func (a Admin) Exec() error {
+	action, ok := adminActions[a.action]
+	if !ok {
+		return fmt.Errorf("unknown admin action %v", a.action)
+	}
+	if action.confirm && !a.confirmed {
+		return fmt.Errorf("%v affects the whole instance at %v, please confirm with --yes", a.action, a.jenkins.Server)
+	}
+
+	resp, err := a.jenkins.Requester.Post(action.endpoint, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%v failed with HTTP %v", a.action, resp.StatusCode)
+	}
+	fmt.Printf("%v %v\n", Good, action.message)
+	return nil
+}
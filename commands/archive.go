@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/bndr/gojenkins"
+)
+
+// archiveLog writes the console output of a build to dir/<job>-<build>.log
+// and returns the path of the file
+func archiveLog(dir, jobName string, number int64, output string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, buildFileName(jobName, number))
+	return path, ioutil.WriteFile(path, []byte(output), 0644)
+}
+
+// archiveFailedLogs archives the console output of the last build of all failed jobs
+func archiveFailedLogs(jenkins *gojenkins.Jenkins, dir string, statuses []JobStatus) {
+	failed := make([]JobStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Result == "FAILURE" || status.Result == "UNSTABLE" {
+			failed = append(failed, status)
+		}
+	}
+
+	forEach(len(failed), func(i int) {
+		job, err := jenkins.GetJob(failed[i].Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot archive logs of %v: %v\n", failed[i].Name, err)
+			return
+		}
+		build, err := job.GetLastBuild()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot archive logs of %v: %v\n", failed[i].Name, err)
+			return
+		}
+		if _, err = archiveLog(dir, failed[i].Name, build.GetBuildNumber(), consoleOutput(build)); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot archive logs of %v: %v\n", failed[i].Name, err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
+)
+
+// artifactDownload is an artifact of the last build of a job
+type artifactDownload struct {
+	job          string
+	build        *gojenkins.Build
+	relativePath string
+}
+
+type Artifacts struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	dir     string
+	// concurrency is the maximum number of parallel downloads, 0 for no limit
+	concurrency int
+	// build is the number or a selector like "lastSuccessful" of the builds, defaults to the last build
+	build string
+}
+
+func NewArtifacts(jenkins *gojenkins.Jenkins, regex, dir string, concurrency int, build string) *Artifacts {
+	return &Artifacts{jenkins, regex, dir, concurrency, build}
+}
+
+func (a Artifacts) Exec() error {
+	jobs, err := job.FindMatchingJobs(a.jenkins, a.regex)
+	if err != nil {
+		return err
+	}
+
+	perJob := make([][]artifactDownload, len(jobs))
+	forEach(len(jobs), func(i int) {
+		jenkinsJob, err := a.jenkins.GetJob(jobs[i].Name)
+		if err != nil {
+			out.Printf("%v %v: %v\n", Unknown, jobs[i].Name, err)
+			return
+		}
+		number, err := selectBuildNumber(jenkinsJob, a.build)
+		if err != nil {
+			out.Printf("%v %v: %v\n", Unknown, jobs[i].Name, err)
+			return
+		}
+		build, err := jenkinsJob.GetBuild(number)
+		if err != nil {
+			out.Printf("%v %v: %v\n", Unknown, jobs[i].Name, err)
+			return
+		}
+		for _, artifact := range build.Raw.Artifacts {
+			perJob[i] = append(perJob[i], artifactDownload{jobs[i].Name, build, artifact.RelativePath})
+		}
+	})
+	var downloads []artifactDownload
+	for _, artifacts := range perJob {
+		downloads = append(downloads, artifacts...)
+	}
+
+	var done, failed int32
+	forEachLimit(len(downloads), a.concurrency, func(i int) {
+		d := downloads[i]
+		path, size, err := a.download(d)
+		n := atomic.AddInt32(&done, 1)
+		if err != nil {
+			atomic.AddInt32(&failed, 1)
+			out.Printf("[%v/%v] %v %v [%v] %v: %v\n", n, len(downloads), Bad, d.job, d.build.GetBuildNumber(), d.relativePath, err)
+			return
+		}
+		out.Printf("[%v/%v] %v %v (%v bytes)\n", n, len(downloads), Good, path, size)
+	})
+	if failed > 0 {
+		return fmt.Errorf("%v of %v artifacts could not be downloaded", failed, len(downloads))
+	}
+	return nil
+}
+
+// download saves an artifact to dir/<job>-<build>/<relative path> and returns the path and size
+func (a Artifacts) download(d artifactDownload) (string, int64, error) {
+	dir := filepath.Join(a.dir, strings.TrimSuffix(buildFileName(d.job, d.build.GetBuildNumber()), ".log"))
+	// The relative path comes from the server and must not escape the directory with "../"
+	path := filepath.Join(dir, filepath.FromSlash(d.relativePath))
+	if !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", 0, fmt.Errorf("refusing to save %q outside of %v", d.relativePath, a.dir)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, err
+	}
+
+	req, err := newRequest(a.jenkins, "GET", escapePath(d.build.Base+"/artifact/"+d.relativePath), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := a.jenkins.Requester.Client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", 0, fmt.Errorf("HTTP %v", resp.StatusCode)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+	size, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return path, size, err
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// assertionPattern matches a single comparison, e.g. "failure==0" or "unstable <= 2"
+var assertionPattern = regexp.MustCompile(`^\s*([a-z_]+)\s*(==|!=|<=|>=|<|>)\s*(\d+)\s*$`)
+
+// assertionCounts are the names which can be used in an assertion
+var assertionCounts = []string{"total", "success", "failure", "unstable", "aborted", "not_built", "running", "disabled", "forbidden", "unknown"}
+
+// assertion is a comparison of the number of jobs with a result against a value
+type assertion struct {
+	text     string
+	name     string
+	operator string
+	value    int
+}
+
+// parseAssertions parses comparisons joined by "&&", e.g. "failure==0 && unstable<=2"
+func parseAssertions(expr string) ([]assertion, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	var assertions []assertion
+	for _, part := range strings.Split(expr, "&&") {
+		match := assertionPattern.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("invalid assertion %q, expected e.g. failure==0", strings.TrimSpace(part))
+		}
+		if !contains(assertionCounts, match[1]) {
+			return nil, fmt.Errorf("unknown count %q in assertion, valid counts are: %v", match[1], strings.Join(assertionCounts, ", "))
+		}
+		value, _ := strconv.Atoi(match[3])
+		assertions = append(assertions, assertion{strings.TrimSpace(part), match[1], match[2], value})
+	}
+	return assertions, nil
+}
+
+// countResults counts the jobs by result, using the names of assertionCounts
+func countResults(statuses []JobStatus) map[string]int {
+	counts := map[string]int{"total": len(statuses)}
+	for _, status := range statuses {
+		name := strings.ToLower(status.Result)
+		if !contains(assertionCounts, name) {
+			name = "unknown"
+		}
+		counts[name]++
+	}
+	return counts
+}
+
+// checkAssertions returns an error listing all assertions which don't hold
+func checkAssertions(assertions []assertion, counts map[string]int) error {
+	var failed []string
+	for _, a := range assertions {
+		if !a.holds(counts[a.name]) {
+			failed = append(failed, fmt.Sprintf("%v (%v is %v)", a.text, a.name, counts[a.name]))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("assertion failed: %v", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (a assertion) holds(count int) bool {
+	switch a.operator {
+	case "==":
+		return count == a.value
+	case "!=":
+		return count != a.value
+	case "<":
+		return count < a.value
+	case "<=":
+		return count <= a.value
+	case ">":
+		return count > a.value
+	case ">=":
+		return count >= a.value
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
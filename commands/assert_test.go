@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAssertions(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    []assertion
+		wantErr string
+	}{
+		{
+			name: "empty",
+			expr: "",
+		},
+		{
+			name: "only whitespace",
+			expr: "  \t ",
+		},
+		{
+			name: "single comparison",
+			expr: "failure==0",
+			want: []assertion{{"failure==0", "failure", "==", 0}},
+		},
+		{
+			name: "joined comparisons with spaces",
+			expr: " failure == 0 &&unstable<=2 ",
+			want: []assertion{
+				{"failure == 0", "failure", "==", 0},
+				{"unstable<=2", "unstable", "<=", 2},
+			},
+		},
+		{
+			name: "all operators",
+			expr: "total!=1 && success<2 && aborted>3 && not_built>=4",
+			want: []assertion{
+				{"total!=1", "total", "!=", 1},
+				{"success<2", "success", "<", 2},
+				{"aborted>3", "aborted", ">", 3},
+				{"not_built>=4", "not_built", ">=", 4},
+			},
+		},
+		{
+			name:    "unknown count",
+			expr:    "failures==0",
+			wantErr: `unknown count "failures"`,
+		},
+		{
+			name:    "unknown operator",
+			expr:    "failure=0",
+			wantErr: `invalid assertion "failure=0"`,
+		},
+		{
+			name:    "negative value",
+			expr:    "failure>-1",
+			wantErr: `invalid assertion "failure>-1"`,
+		},
+		{
+			name:    "missing value",
+			expr:    "failure==",
+			wantErr: `invalid assertion "failure=="`,
+		},
+		{
+			name:    "upper case count",
+			expr:    "FAILURE==0",
+			wantErr: `invalid assertion "FAILURE==0"`,
+		},
+		{
+			name:    "empty part",
+			expr:    "failure==0 &&",
+			wantErr: `invalid assertion ""`,
+		},
+		{
+			name:    "or is not supported",
+			expr:    "failure==0 || unstable==0",
+			wantErr: `invalid assertion "failure==0 || unstable==0"`,
+		},
+		{
+			name:    "malformed part after a valid one",
+			expr:    "failure==0 && unstable",
+			wantErr: `invalid assertion "unstable"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseAssertions(test.expr)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCountResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []JobStatus
+		want     map[string]int
+	}{
+		{
+			name: "no jobs",
+			want: map[string]int{"total": 0},
+		},
+		{
+			name: "results are case insensitive",
+			statuses: []JobStatus{
+				{Result: "SUCCESS"}, {Result: "success"}, {Result: "FAILURE"}, {Result: "NOT_BUILT"},
+			},
+			want: map[string]int{"total": 4, "success": 2, "failure": 1, "not_built": 1},
+		},
+		{
+			name:     "unexpected results are unknown",
+			statuses: []JobStatus{{Result: "WEIRD"}, {Result: ""}, {Result: "UNKNOWN"}},
+			want:     map[string]int{"total": 3, "unknown": 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := countResults(test.statuses); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckAssertions(t *testing.T) {
+	counts := map[string]int{"total": 5, "failure": 1, "unstable": 2}
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "all hold", expr: "failure<=1 && unstable==2 && total>4"},
+		{name: "missing counts are zero", expr: "aborted==0"},
+		{name: "boundary below", expr: "failure<1", wantErr: "failure<1 (failure is 1)"},
+		{name: "boundary above", expr: "total>5", wantErr: "total>5 (total is 5)"},
+		{
+			name:    "all failures are listed",
+			expr:    "failure==0 && unstable==2 && unstable!=2",
+			wantErr: "assertion failed: failure==0 (failure is 1), unstable!=2 (unstable is 2)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertions, err := parseAssertions(test.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = checkAssertions(assertions, counts)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package commands
 
 import (
 	"fmt"
-	"sync"
+	"strings"
 
 	"github.com/bndr/gojenkins"
 	"github.com/mre/riffraff/job"
@@ -11,10 +11,29 @@ import (
 type Build struct {
 	jenkins *gojenkins.Jenkins
 	regex   string
+	options BuildOptions
 }
 
-func NewBuild(jenkins *gojenkins.Jenkins, regex string) *Build {
-	return &Build{jenkins, regex}
+// BuildOptions control how builds are triggered
+type BuildOptions struct {
+	// Wait for the triggered builds to finish
+	Wait bool
+	// Node is the label of the nodes the builds should run on.
+	// It is passed to a node or label parameter of the job, jobs without one are skipped.
+	Node string
+	// Params are the build parameters. Values of choice parameters are validated.
+	Params map[string]string
+}
+
+// parameterDefinition is a parameter of a job, including the allowed values of choice parameters
+type parameterDefinition struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Choices []string `json:"choices"`
+}
+
+func NewBuild(jenkins *gojenkins.Jenkins, regex string, options BuildOptions) *Build {
+	return &Build{jenkins, regex, options}
 }
 
 func (b Build) Exec() error {
@@ -23,25 +42,126 @@ func (b Build) Exec() error {
 		return err
 	}
 
-	var wg sync.WaitGroup
-	for _, job := range jobs {
-		wg.Add(1)
-		go func(job gojenkins.InnerJob) {
-			defer wg.Done()
-
-			id, err := b.jenkins.BuildJob(job.Name)
-			if err != nil {
-				fmt.Printf("Triggering build for %v failed: %v\n", job.Name, err)
-				return
-			}
-			build, err := b.jenkins.GetBuild(job.Name, id)
-			if err != nil {
-				fmt.Printf("Getting build for %v [%v] failed: %v\n", job.Name, id, err)
-				return
-			}
-			fmt.Printf("Triggered build for %v [%v] %v\n", job.Name, id, build.GetUrl())
-		}(job)
-	}
-	wg.Wait()
+	forEach(len(jobs), func(i int) {
+		job := jobs[i]
+
+		params, err := b.params(job)
+		if err != nil {
+			out.Printf("Not triggering build for %v: %v\n", job.Name, err)
+			return
+		}
+
+		throttleTrigger()
+		// BuildJob returns the id of the queue item, not a build number
+		queueID, err := b.jenkins.BuildJob(job.Name, params)
+		if err != nil {
+			out.Printf("Triggering build for %v failed: %v\n", job.Name, err)
+			return
+		}
+		if queueID == 0 {
+			out.Printf("Build for %v is already queued\n", job.Name)
+			return
+		}
+		if !b.options.Wait {
+			out.Printf("Queued build for %v [queue item %v]\n", job.Name, queueID)
+			return
+		}
+
+		id, err := waitForQueuedBuild(b.jenkins, queueID)
+		if err != nil {
+			out.Printf("Waiting for build of %v failed: %v\n", job.Name, err)
+			return
+		}
+		build, err := b.jenkins.GetBuild(job.Name, id)
+		if err != nil {
+			out.Printf("Getting build for %v [%v] failed: %v\n", job.Name, id, err)
+			return
+		}
+		out.Printf("Triggered build for %v [%v] %v\n", job.Name, id, build.GetUrl())
+		if progress, ok := estimatedProgress(build); ok && build.Raw.Building {
+			out.Printf("Waiting for build of %v [%v], %v\n", job.Name, id, progressText(progress))
+		}
+
+		if err = waitForBuild(build); err != nil {
+			out.Printf("Waiting for build of %v [%v] failed: %v\n", job.Name, id, err)
+			return
+		}
+		out.Printf("%v %v [%v] finished: %v\n", resultMarker(build.GetResult()), job.Name, id, build.GetResult())
+	})
 	return nil
 }
+
+// params returns the build parameters for a job
+func (b Build) params(job gojenkins.InnerJob) (map[string]string, error) {
+	params := map[string]string{}
+	if b.options.Node == "" && len(b.options.Params) == 0 {
+		return params, nil
+	}
+
+	definitions, err := b.parameterDefinitions(job)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range b.options.Params {
+		if err = validateParam(definitions, name, value); err != nil {
+			return nil, err
+		}
+		params[name] = value
+	}
+
+	if b.options.Node == "" {
+		return params, nil
+	}
+	// Node and label parameters are provided by the NodeLabel Parameter plugin
+	for _, definition := range definitions {
+		if definition.Type == "NodeParameterDefinition" || definition.Type == "LabelParameterDefinition" {
+			params[definition.Name] = b.options.Node
+			return params, nil
+		}
+	}
+	return nil, fmt.Errorf("the job has no node or label parameter to run it on %v", b.options.Node)
+}
+
+// parameterDefinitions fetches the parameters of a job.
+// gojenkins' GetParameters doesn't include the choices, so they are queried directly.
+func (b Build) parameterDefinitions(job gojenkins.InnerJob) ([]parameterDefinition, error) {
+	var response struct {
+		Property []struct {
+			ParameterDefinitions []parameterDefinition `json:"parameterDefinitions"`
+		} `json:"property"`
+	}
+	jenkinsJob, err := b.jenkins.GetJob(job.Name)
+	if err != nil {
+		return nil, err
+	}
+	query := map[string]string{"tree": "property[parameterDefinitions[name,type,choices]]"}
+	resp, err := b.jenkins.Requester.GetJSON(jenkinsJob.Base, &response, query)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("cannot get parameters: HTTP %v", resp.StatusCode)
+	}
+
+	var definitions []parameterDefinition
+	for _, property := range response.Property {
+		definitions = append(definitions, property.ParameterDefinitions...)
+	}
+	return definitions, nil
+}
+
+// validateParam checks that the job has the parameter and,
+// for choice parameters, that the value is one of the choices
+func validateParam(definitions []parameterDefinition, name, value string) error {
+	for _, definition := range definitions {
+		if definition.Name != name {
+			continue
+		}
+		if definition.Type == "ChoiceParameterDefinition" && !contains(definition.Choices, value) {
+			return fmt.Errorf("invalid value %q for parameter %v, valid choices are: %v",
+				value, name, strings.Join(definition.Choices, ", "))
+		}
+		return nil
+	}
+	return fmt.Errorf("the job has no parameter %v", name)
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
+)
+
+type Buildable struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	// concurrency is the maximum number of jobs checked at the same time, 0 for no limit
+	concurrency int
+}
+
+func NewBuildable(jenkins *gojenkins.Jenkins, regex string, concurrency int) *Buildable {
+	return &Buildable{jenkins, regex, concurrency}
+}
+
+func (b Buildable) Exec() error {
+	// Old versions start a build on a GET of the build endpoint
+	if strings.HasPrefix(b.jenkins.Version, "1.") {
+		return fmt.Errorf("checking the Build permission needs Jenkins 2 or newer, found %v", b.jenkins.Version)
+	}
+	jobs, err := job.FindMatchingJobs(b.jenkins, b.regex)
+	if err != nil {
+		return err
+	}
+	jobs = withoutDisabled(job.OfType(jobs, "job"))
+
+	allowed := make([]bool, len(jobs))
+	errs := make([]error, len(jobs))
+	forEachLimit(len(jobs), b.concurrency, func(i int) {
+		allowed[i], errs[i] = b.canBuild(jobs[i])
+	})
+
+	count := 0
+	for i, j := range jobs {
+		switch {
+		case errs[i] != nil:
+			fmt.Fprintf(os.Stderr, "%v %v: %v\n", Unknown, j.Name, errs[i])
+		case allowed[i]:
+			printEntry(j.Name)
+			count++
+		}
+	}
+	printCount("You can build %v of %v jobs matching %q", count, len(jobs), b.regex)
+	return nil
+}
+
+// canBuild tells whether the user has the Build permission on a job.
+// Jenkins checks the permission before it rejects a GET of the build endpoint,
+// so a 403 means it's missing, while 405 Method Not Allowed or the parameters form
+// of a parameterized job means it's there. Anything else, like a login page after
+// an SSO redirect, proves nothing and is an error.
+func (b Buildable) canBuild(j gojenkins.InnerJob) (bool, error) {
+	jenkinsJob, err := b.jenkins.GetJob(j.Name)
+	if err != nil {
+		return false, err
+	}
+	path := escapePath(jenkinsJob.Base + "/build")
+	req, err := newRequest(b.jenkins, "GET", path, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := b.jenkins.Requester.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusMethodNotAllowed:
+		return true, nil
+	case http.StatusForbidden:
+		return false, nil
+	case http.StatusOK:
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return false, err
+		}
+		if !strings.HasSuffix(resp.Request.URL.EscapedPath(), path) || !strings.Contains(string(body), `name="parameters"`) {
+			return false, fmt.Errorf("cannot check the permission: %v is not the build form", resp.Request.URL)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("cannot check the permission: HTTP %v", resp.StatusCode)
+}
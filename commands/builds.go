@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+// buildList is the list of all builds of a job, newest first
+type buildList struct {
+	Builds []struct {
+		Number    int64  `json:"number"`
+		Result    string `json:"result"`
+		Building  bool   `json:"building"`
+		Timestamp int64  `json:"timestamp"`
+		URL       string `json:"url"`
+	} `json:"allBuilds"`
+}
+
+// buildSelectors resolve Jenkins' symbolic build names to the build of a job they point to
+var buildSelectors = map[string]func(job *gojenkins.JobResponse) gojenkins.JobBuild{
+	"last":             func(job *gojenkins.JobResponse) gojenkins.JobBuild { return job.LastBuild },
+	"lastCompleted":    func(job *gojenkins.JobResponse) gojenkins.JobBuild { return job.LastCompletedBuild },
+	"lastSuccessful":   func(job *gojenkins.JobResponse) gojenkins.JobBuild { return job.LastSuccessfulBuild },
+	"lastStable":       func(job *gojenkins.JobResponse) gojenkins.JobBuild { return job.LastStableBuild },
+	"lastUnstable":     func(job *gojenkins.JobResponse) gojenkins.JobBuild { return job.LastUnstableBuild },
+	"lastFailed":       func(job *gojenkins.JobResponse) gojenkins.JobBuild { return job.LastFailedBuild },
+	"lastUnsuccessful": func(job *gojenkins.JobResponse) gojenkins.JobBuild { return job.LastUnsuccessfulBuild },
+}
+
+// selectBuildNumber resolves a build number or a symbolic name like "lastSuccessful".
+// An empty selector is the last build.
+func selectBuildNumber(job *gojenkins.Job, selector string) (int64, error) {
+	if selector == "" {
+		selector = "last"
+	}
+	if number, err := strconv.ParseInt(selector, 10, 64); err == nil {
+		return number, nil
+	}
+	pointer, ok := buildSelectors[selector]
+	if !ok {
+		return 0, fmt.Errorf("invalid build %q, expected a number or one of last, lastCompleted, lastSuccessful, lastStable, lastUnstable, lastFailed and lastUnsuccessful", selector)
+	}
+	build := pointer(job.Raw)
+	if build.Number == 0 {
+		return 0, fmt.Errorf("%v has no %v build", job.GetName(), selector)
+	}
+	return build.Number, nil
+}
+
+type Builds struct {
+	jenkins *gojenkins.Jenkins
+	jobName string
+	// sinceBuild is a build number or selector, only newer builds are listed
+	sinceBuild string
+}
+
+func NewBuilds(jenkins *gojenkins.Jenkins, jobName string, sinceBuild string) *Builds {
+	return &Builds{jenkins, jobName, sinceBuild}
+}
+
+func (b Builds) Exec() error {
+	job, err := b.jenkins.GetJob(b.jobName)
+	if err != nil {
+		return err
+	}
+	var since int64
+	if b.sinceBuild != "" {
+		if since, err = selectBuildNumber(job, b.sinceBuild); err != nil {
+			return err
+		}
+	}
+
+	var builds buildList
+	query := map[string]string{"tree": "allBuilds[number,result,building,timestamp,url]"}
+	if _, err = b.jenkins.Requester.GetJSON(job.Base, &builds, query); err != nil {
+		return err
+	}
+	for _, build := range builds.Builds {
+		if build.Number <= since {
+			continue
+		}
+		result := build.Result
+		if build.Building {
+			result = "RUNNING"
+		}
+		started := time.Unix(0, build.Timestamp*int64(time.Millisecond))
+		fmt.Printf("%v [%v] %v %v (%v)\n", resultMarker(result), build.Number, started.Format("2006-01-02 15:04:05"), result, build.URL)
+	}
+	return nil
+}
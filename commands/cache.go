@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+// buildFileName returns a safe file name for a build of a job, e.g. "my-job-42.log".
+// All other characters are escaped instead of replaced, so "my job", "my_job" and a job
+// in a folder like "team/app" each get a file of their own.
+func buildFileName(jobName string, number int64) string {
+	return fmt.Sprintf("%v-%v.log", url.QueryEscape(jobName), number)
+}
+
+// consoleOutput returns the console output of a build.
+// The logs of finished builds never change, so they are cached on disk.
+func consoleOutput(build *gojenkins.Build) string {
+	if build.Raw.Building || build.GetUrl() == "" {
+		return build.GetConsoleOutput()
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return build.GetConsoleOutput()
+	}
+	// The build URL tells apart instances and jobs whose names only differ in characters
+	// which aren't allowed in file names
+	cacheFile := filepath.Join(cacheDir, "riffraff", "console", fmt.Sprintf("%x.log", sha256.Sum256([]byte(build.GetUrl()))))
+	if cached, err := ioutil.ReadFile(cacheFile); err == nil {
+		return string(cached)
+	}
+
+	output := build.GetConsoleOutput()
+	// Caching is best effort, failures only cost another download next time
+	if err = os.MkdirAll(filepath.Dir(cacheFile), 0700); err == nil {
+		_ = ioutil.WriteFile(cacheFile, []byte(output), 0600)
+	}
+	return output
+}
+
+// cachedResult is the status of a job as it was at the given time
+type cachedResult struct {
+	Status JobStatus `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+// resultCachePath is the file the job statuses are cached in between runs of riffraff
+func resultCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "riffraff", "status-cache.json"), nil
+}
+
+// loadResultCache reads the cached job statuses by job URL.
+// A missing or broken cache is treated as empty.
+func loadResultCache() map[string]cachedResult {
+	results := map[string]cachedResult{}
+	path, err := resultCachePath()
+	if err != nil {
+		return results
+	}
+	if data, err := ioutil.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &results)
+	}
+	return results
+}
+
+// saveResultCache writes the cached job statuses
+func saveResultCache(results map[string]cachedResult) error {
+	path, err := resultCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
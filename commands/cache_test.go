@@ -0,0 +1,29 @@
+package commands
+
+import "testing"
+
+func TestBuildFileName(t *testing.T) {
+	tests := []struct {
+		jobName string
+		want    string
+	}{
+		{"my-job", "my-job-42.log"},
+		{"my_job", "my_job-42.log"},
+		{"my job", "my+job-42.log"},
+		{"my+job", "my%2Bjob-42.log"},
+		{"team/app", "team%2Fapp-42.log"},
+		{"team_app", "team_app-42.log"},
+		{"../etc", "..%2Fetc-42.log"},
+	}
+	seen := map[string]string{}
+	for _, test := range tests {
+		got := buildFileName(test.jobName, 42)
+		if got != test.want {
+			t.Errorf("buildFileName(%q, 42) = %q, want %q", test.jobName, got, test.want)
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("%q and %q both map to %q", other, test.jobName, got)
+		}
+		seen[got] = test.jobName
+	}
+}
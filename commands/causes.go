@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+)
+
+// causeKinds maps parts of the class names of build causes to the kinds --cause filters on
+var causeKinds = []struct{ class, kind string }{
+	{"TimerTrigger", "timer"},
+	{"UserIdCause", "user"},
+	{"UserCause", "user"},
+	{"SCMTrigger", "scm"},
+	{"UpstreamCause", "upstream"},
+	{"RemoteCause", "remote"},
+	{"BranchIndexingCause", "indexing"},
+}
+
+// buildCauses describes why a build was started, e.g. "timer" or "user:jane".
+// The causes are read from the actions of the build which were fetched with it.
+func buildCauses(build *gojenkins.Build) []string {
+	var causes []string
+	for _, action := range build.Raw.Actions {
+		for _, cause := range action.Causes {
+			kind := causeKind(fmt.Sprint(cause["_class"]), fmt.Sprint(cause["shortDescription"]))
+			if user, ok := cause["userId"].(string); ok && kind == "user" {
+				kind += ":" + user
+			}
+			causes = append(causes, kind)
+		}
+	}
+	return causes
+}
+
+// causeKind returns the kind of a build cause by its class,
+// or by its description on old Jenkins versions which don't report the class
+func causeKind(class, description string) string {
+	for _, k := range causeKinds {
+		if strings.Contains(class, k.class) {
+			return k.kind
+		}
+	}
+	switch {
+	case strings.HasPrefix(description, "Started by timer"):
+		return "timer"
+	case strings.HasPrefix(description, "Started by user"):
+		return "user"
+	}
+	return "other"
+}
+
+// causedBy tells whether any of the causes is of the given kind and by the given user.
+// Empty filters match everything.
+func causedBy(causes []string, kind, user string) bool {
+	if kind == "" && user == "" {
+		return true
+	}
+	for _, cause := range causes {
+		parts := strings.SplitN(cause, ":", 2)
+		if kind != "" && parts[0] != kind {
+			continue
+		}
+		if user != "" && (len(parts) < 2 || !strings.EqualFold(parts[1], user)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
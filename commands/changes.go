@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
+)
+
+// resultChange is an entry of the local log of result transitions
+type resultChange struct {
+	Job  string    `json:"job"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Time time.Time `json:"time"`
+}
+
+// stateDir returns the directory riffraff keeps its local state in
+func stateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".riffraff"), nil
+}
+
+// finishedResults are the results of finished builds, only changes between them are recorded.
+// Otherwise every build would add a change to RUNNING and back, burying the real ones.
+var finishedResults = map[string]bool{"SUCCESS": true, "FAILURE": true, "UNSTABLE": true, "ABORTED": true, "NOT_BUILT": true}
+
+// recordChanges compares the finished results with the last known ones,
+// appends all transitions to the change log and updates the last known results.
+// The results are keyed by job URL, so instances with jobs of the same name don't mix.
+func recordChanges(statuses []JobStatus) error {
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	snapshotFile := filepath.Join(dir, "results.json")
+	last := map[string]string{}
+	if data, err := ioutil.ReadFile(snapshotFile); err == nil {
+		if err = json.Unmarshal(data, &last); err != nil {
+			return fmt.Errorf("cannot read %v: %v", snapshotFile, err)
+		}
+	}
+
+	log, err := os.OpenFile(filepath.Join(dir, "changes.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	now := time.Now()
+	encoder := json.NewEncoder(log)
+	for _, status := range statuses {
+		if !finishedResults[status.Result] || status.URL == "" {
+			continue
+		}
+		if from, ok := last[status.URL]; ok && from != status.Result {
+			if err = encoder.Encode(resultChange{status.Name, from, status.Result, now}); err != nil {
+				return err
+			}
+		}
+		last[status.URL] = status.Result
+	}
+
+	data, err := json.Marshal(last)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(snapshotFile, data, 0600)
+}
+
+type Changes struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+}
+
+func NewChanges(jenkins *gojenkins.Jenkins, regex string) *Changes {
+	return &Changes{jenkins, regex}
+}
+
+func (c Changes) Exec() error {
+	matcher, err := job.NewMatcher(c.regex)
+	if err != nil {
+		return err
+	}
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+
+	log, err := os.Open(filepath.Join(dir, "changes.log"))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no changes recorded yet, run status first")
+	}
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	scanner := bufio.NewScanner(log)
+	for scanner.Scan() {
+		var change resultChange
+		if err = json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			return err
+		}
+		if matcher.Match(change.Job) {
+			fmt.Printf("%v %v %v: %v → %v\n", change.Time.Format("2006-01-02 15:04:05"), resultMarker(change.To), change.Job, change.From, change.To)
+		}
+	}
+	return scanner.Err()
+}
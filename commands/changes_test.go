@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecordChanges(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	prod := "https://prod.example.com/job/web/"
+	staging := "https://staging.example.com/job/web/"
+	runs := [][]JobStatus{
+		{{Name: "web", URL: prod, Result: "SUCCESS"}, {Name: "web", URL: staging, Result: "FAILURE"}},
+		{{Name: "web", URL: prod, Result: "RUNNING"}, {Name: "web", URL: staging, Result: "FAILURE"}},
+		{{Name: "web", URL: prod, Result: "FAILURE"}, {Name: "web", URL: staging, Result: "UNKNOWN"}},
+		{{Name: "web", URL: prod, Result: "FAILURE"}, {Name: "web", URL: staging, Result: "SUCCESS"}},
+	}
+	for _, statuses := range runs {
+		if err := recordChanges(statuses); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log, err := os.Open(filepath.Join(home, ".riffraff", "changes.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	var got [][2]string
+	scanner := bufio.NewScanner(log)
+	for scanner.Scan() {
+		var change resultChange
+		if err = json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, [2]string{change.From, change.To})
+	}
+	want := [][2]string{{"SUCCESS", "FAILURE"}, {"FAILURE", "SUCCESS"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// confirmThreshold is the number of jobs from which on destructive commands
+// ask to type the number of affected jobs, 0 never asks
+var confirmThreshold = 10
+
+// SetConfirmThreshold sets the number of jobs from which on destructive commands need a typed confirmation.
+// A fat-fingered pattern like ".*" shouldn't be able to affect a whole instance with a single y.
+func SetConfirmThreshold(n int) {
+	confirmThreshold = n
+}
+
+// confirmWide asks to type the number of affected jobs if it reaches the threshold.
+// It fails unless exactly that number is typed.
+func confirmWide(action string, count int) error {
+	if confirmThreshold <= 0 || count < confirmThreshold {
+		return nil
+	}
+	phrase := strconv.Itoa(count)
+	fmt.Fprintf(os.Stderr, "This will %v %v jobs. Type %v to continue: ", action, count, phrase)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if strings.TrimSpace(answer) != phrase {
+		return fmt.Errorf("not confirmed, nothing was done (use --confirm-threshold to change when to ask)")
+	}
+	return nil
+}
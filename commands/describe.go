@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+)
+
+// timerTriggerPattern extracts the cron spec of a timer trigger from a job's config.xml
+var timerTriggerPattern = regexp.MustCompile(`(?s)<hudson\.triggers\.TimerTrigger>\s*<spec>([^<]*)</spec>`)
+
+// nextRunPattern extracts the next run from Jenkins' check of a timer spec,
+// e.g. "Would last have run at …; would next run at Saturday, 17 October 2026, 03:17:23 UTC."
+var nextRunPattern = regexp.MustCompile(`would next run at ([^<]*?)\.?\s*(<|$)`)
+
+type Describe struct {
+	jenkins *gojenkins.Jenkins
+	jobName string
+}
+
+func NewDescribe(jenkins *gojenkins.Jenkins, jobName string) *Describe {
+	return &Describe{jenkins, jobName}
+}
+
+func (d Describe) Exec() error {
+	job, err := d.jenkins.GetJob(d.jobName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v (%v)\n", job.Raw.Name, job.Raw.URL)
+	if job.Raw.Description != "" {
+		fmt.Printf("Description: %v\n", job.Raw.Description)
+	}
+	if job.Raw.Buildable {
+		fmt.Printf("State: %v enabled\n", Good)
+	} else {
+		fmt.Printf("State: %v disabled\n", Disabled)
+	}
+
+	if job.Raw.LastBuild.Number == 0 {
+		fmt.Println("Last build: none")
+	} else if build, err := job.GetLastBuild(); err != nil {
+		fmt.Printf("Last build: %v (%v)\n", job.Raw.LastBuild.Number, err)
+	} else {
+		result := build.GetResult()
+		if build.Raw.Building {
+			result = "RUNNING"
+		}
+		fmt.Printf("Last build: %v %v [%v] %v\n", resultMarker(result), result, build.GetBuildNumber(), build.GetTimestamp().Format("2006-01-02 15:04:05"))
+	}
+
+	// Reading the configuration requires the ExtendedRead permission
+	if config, err := job.GetConfig(); err != nil {
+		fmt.Printf("Schedule: unknown (%v)\n", err)
+	} else if match := timerTriggerPattern.FindStringSubmatch(config); match != nil {
+		fmt.Printf("Schedule: %v\n", strings.TrimSpace(match[1]))
+		if next, err := d.nextRun(job, html.UnescapeString(match[1])); err != nil {
+			fmt.Printf("Next build: unknown (%v)\n", err)
+		} else {
+			fmt.Printf("Next build: %v\n", next)
+		}
+	} else {
+		fmt.Println("Schedule: none")
+	}
+
+	if !job.Raw.InQueue {
+		fmt.Println("Queued: no")
+	} else if item, ok := job.Raw.QueueItem.(map[string]interface{}); ok && item["why"] != nil {
+		fmt.Printf("Queued: yes (%v)\n", item["why"])
+	} else {
+		fmt.Println("Queued: yes")
+	}
+	return nil
+}
+
+// nextRun asks Jenkins when a timer spec triggers the next build of a job.
+// The "H" in specs like "H 3 * * *" is a hash of the job name, which only Jenkins can resolve,
+// in the time zone of the spec or the server, so its check of the spec is used.
+func (d Describe) nextRun(job *gojenkins.Job, spec string) (string, error) {
+	path := escapePath(job.Base + "/descriptorByName/hudson.triggers.TimerTrigger/checkSpec")
+	req, err := newRequest(d.jenkins, "POST", path, strings.NewReader(url.Values{"value": {spec}}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// The message is localized
+	req.Header.Set("Accept-Language", "en")
+	resp, err := d.jenkins.Requester.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("HTTP %v", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	match := nextRunPattern.FindStringSubmatch(string(body))
+	if match == nil {
+		return "", fmt.Errorf("no next run in the response of Jenkins")
+	}
+	return html.UnescapeString(match[1]), nil
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bndr/gojenkins"
+)
+
+func TestNextRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "ok",
+			response: `<div class=ok><img src="/static/ok.png">Would last have run at Friday, 16 October 2026, 03:17:00 Coordinated Universal Time; ` +
+				`would next run at Saturday, 17 October 2026, 03:17:00 Coordinated Universal Time.</div>`,
+			want: "Saturday, 17 October 2026, 03:17:00 Coordinated Universal Time",
+		},
+		{
+			name: "with a warning",
+			response: `<div class=warning>Spread load evenly by using &lsquo;H 3 * * *&rsquo; rather than &lsquo;0 3 * * *&rsquo;</div>` +
+				`<div class=ok>Would last have run at Friday; would next run at Saturday &amp; later.</div>`,
+			want: "Saturday & later",
+		},
+		{
+			name:     "invalid spec",
+			response: `<div class=error>Invalid input: &quot;x&quot;</div>`,
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var spec string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/job/my job/descriptorByName/hudson.triggers.TimerTrigger/checkSpec" {
+					http.NotFound(w, r)
+					return
+				}
+				spec = r.FormValue("value")
+				w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			jenkins := gojenkins.CreateJenkins(server.Client(), server.URL)
+			job := &gojenkins.Job{Base: "/job/my job"}
+			got, err := Describe{jenkins: jenkins}.nextRun(job, "H 3 * * *")
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			if spec != "H 3 * * *" {
+				t.Errorf("Jenkins got spec %q", spec)
+			}
+		})
+	}
+}
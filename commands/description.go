@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bndr/gojenkins"
+)
+
+// SetDescription sets the description of a build.
+// Badges can't be set, Jenkins only adds them from pipeline steps or the script console.
+type SetDescription struct {
+	jenkins     *gojenkins.Jenkins
+	jobName     string
+	build       string
+	description string
+}
+
+func NewSetDescription(jenkins *gojenkins.Jenkins, jobName, build, description string) *SetDescription {
+	return &SetDescription{jenkins, jobName, build, description}
+}
+
+func (s SetDescription) Exec() error {
+	job, err := s.jenkins.GetJob(s.jobName)
+	if err != nil {
+		return err
+	}
+	number, err := selectBuildNumber(job, s.build)
+	if err != nil {
+		return err
+	}
+	build, err := job.GetBuild(number)
+	if err != nil {
+		return err
+	}
+	if err = build.SetDescription(s.description); err != nil {
+		return fmt.Errorf("cannot set the description of %v [%v]: %v", s.jobName, number, err)
+	}
+	fmt.Printf("%v Set the description of %v [%v] (%v)\n", Good, s.jobName, number, build.GetUrl())
+	return nil
+}
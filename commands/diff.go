@@ -12,10 +12,12 @@ type Diff struct {
 	jobName string
 	build1  int64
 	build2  int64
+	// sinceBuild diffs this build against the last build instead of build1 and build2
+	sinceBuild int64
 }
 
-func NewDiff(jenkins *gojenkins.Jenkins, jobName string, build1, build2 int64) *Diff {
-	return &Diff{jenkins, jobName, build1, build2}
+func NewDiff(jenkins *gojenkins.Jenkins, jobName string, build1, build2, sinceBuild int64) *Diff {
+	return &Diff{jenkins, jobName, build1, build2, sinceBuild}
 }
 
 func (d Diff) Exec() error {
@@ -23,6 +25,12 @@ func (d Diff) Exec() error {
 	if err != nil {
 		return err
 	}
+	if d.sinceBuild > 0 {
+		d.build1, d.build2 = d.sinceBuild, build.Raw.LastBuild.Number
+	}
+	if d.build1 == 0 || d.build2 == 0 {
+		return fmt.Errorf("please pass two builds or --since-build")
+	}
 
 	build1Logs, err := build.GetBuild(d.build1)
 	if err != nil {
@@ -42,7 +50,7 @@ func (d Diff) Exec() error {
 		Context:  3,
 	}
 	text, _ := difflib.GetUnifiedDiffString(diff)
-	fmt.Printf(text)
+	fmt.Print(text)
 
 	return nil
 }
@@ -0,0 +1,5 @@
+// Package commands implements the riffraff subcommands.
+//
+// Besides running them with Exec, the logic can be reused from other Go programs,
+// e.g. NewStatus(jenkins, regex, StatusOptions{}).Collect() returns the status of all matching jobs.
+package commands
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// AuthError means Jenkins rejected the credentials
+type AuthError struct{ Err error }
+
+func (e *AuthError) Error() string { return fmt.Sprintf("authentication failed: %v", e.Err) }
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// PermissionError means the user lacks the permission for a request
+type PermissionError struct{ Err error }
+
+func (e *PermissionError) Error() string { return fmt.Sprintf("permission denied: %v", e.Err) }
+func (e *PermissionError) Unwrap() error { return e.Err }
+
+// NotFoundError means a job, build or endpoint doesn't exist
+type NotFoundError struct{ Err error }
+
+func (e *NotFoundError) Error() string { return fmt.Sprintf("not found: %v", e.Err) }
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// ConnectionError means Jenkins couldn't be reached
+type ConnectionError struct{ Err error }
+
+func (e *ConnectionError) Error() string { return fmt.Sprintf("cannot connect to Jenkins: %v", e.Err) }
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// UnknownResultError means the result of some jobs couldn't be determined
+type UnknownResultError struct{ Err error }
+
+func (e *UnknownResultError) Error() string { return fmt.Sprintf("unknown result: %v", e.Err) }
+func (e *UnknownResultError) Unwrap() error { return e.Err }
+
+// Classify wraps an error returned by gojenkins into one of the error types above,
+// so callers can tell them apart with errors.As. Other errors are returned unchanged.
+func Classify(err error) error {
+	var (
+		authErr       *AuthError
+		permissionErr *PermissionError
+		notFoundErr   *NotFoundError
+		connectionErr *ConnectionError
+		unknownErr    *UnknownResultError
+		netErr        net.Error
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &authErr), errors.As(err, &permissionErr), errors.As(err, &notFoundErr), errors.As(err, &connectionErr),
+		errors.As(err, &unknownErr):
+		return err
+	case errors.As(err, &netErr), errors.Is(err, syscall.ECONNREFUSED):
+		return &ConnectionError{err}
+	}
+	// gojenkins reports unexpected status codes as errors with the bare code
+	switch err.Error() {
+	case "401":
+		return &AuthError{fmt.Errorf("HTTP 401")}
+	case "403":
+		return &PermissionError{fmt.Errorf("HTTP 403")}
+	case "404":
+		return &NotFoundError{fmt.Errorf("HTTP 404")}
+	}
+	return err
+}
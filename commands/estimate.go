@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+// estimatedProgress returns how far along a running build is in percent,
+// based on its start time and the duration Jenkins estimates from previous builds.
+// It returns false if there is no estimate.
+func estimatedProgress(build *gojenkins.Build) (int, bool) {
+	estimated := time.Duration(build.Raw.EstimatedDuration) * time.Millisecond
+	if estimated <= 0 {
+		return 0, false
+	}
+	elapsed := time.Since(build.GetTimestamp())
+	return int(elapsed * 100 / estimated), true
+}
+
+// progressText describes the progress of a running build, e.g. "running (estimated 80% complete)"
+func progressText(progress int) string {
+	if progress > 100 {
+		return fmt.Sprintf("running (%v%% of the estimated duration, overdue)", progress)
+	}
+	return fmt.Sprintf("running (estimated %v%% complete)", progress)
+}
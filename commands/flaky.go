@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
+)
+
+// recentBuilds are the newest builds of a job, newest first
+type recentBuilds struct {
+	Builds []struct {
+		Number int64  `json:"number"`
+		Result string `json:"result"`
+	} `json:"builds"`
+}
+
+// flakiness tells how often the result of a job flipped between its recent builds
+type flakiness struct {
+	name   string
+	builds int
+	flips  int
+	err    error
+}
+
+// rate is the share of consecutive builds with a different result
+func (f flakiness) rate() float64 {
+	if f.builds < 2 {
+		return 0
+	}
+	return float64(f.flips) / float64(f.builds-1)
+}
+
+type Flaky struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	// sample is the number of recent builds inspected per job
+	sample int
+}
+
+func NewFlaky(jenkins *gojenkins.Jenkins, regex string, sample int) *Flaky {
+	return &Flaky{jenkins, regex, sample}
+}
+
+func (f Flaky) Exec() error {
+	if f.sample < 2 {
+		return fmt.Errorf("the sample needs at least 2 builds, got %v", f.sample)
+	}
+	jobs, err := job.FindMatchingJobs(f.jenkins, f.regex)
+	if err != nil {
+		return err
+	}
+
+	results := make([]flakiness, len(jobs))
+	forEach(len(jobs), func(i int) {
+		results[i] = f.flakiness(jobs[i])
+	})
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].rate() > results[j].rate()
+	})
+
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Printf("%v %v: %v\n", Unknown, r.name, r.err)
+			continue
+		}
+		marker := Good
+		if r.flips > 0 {
+			marker = Unstable
+		}
+		fmt.Printf("%v %v: %.0f%% flaky (%v flips in %v builds)\n", marker, r.name, r.rate()*100, r.flips, r.builds)
+	}
+	return nil
+}
+
+// flakiness counts the result flips between the finished builds among the last sample builds of a job.
+// Only fetching a sample keeps this fast for jobs with thousands of builds.
+func (f Flaky) flakiness(j gojenkins.InnerJob) flakiness {
+	path, err := jobPath(f.jenkins.Server, j.Url)
+	if err != nil {
+		return flakiness{name: j.Name, err: err}
+	}
+	var recent recentBuilds
+	query := map[string]string{"tree": fmt.Sprintf("builds[number,result]{0,%d}", f.sample)}
+	resp, err := f.jenkins.Requester.GetJSON(path, &recent, query)
+	if err != nil {
+		return flakiness{name: j.Name, err: err}
+	}
+	if resp.StatusCode != 200 {
+		return flakiness{name: j.Name, err: fmt.Errorf("cannot list builds: HTTP %v", resp.StatusCode)}
+	}
+
+	result := flakiness{name: j.Name}
+	var last string
+	for _, build := range recent.Builds {
+		// Running builds have no result yet and aborted ones say nothing about flakiness
+		if build.Result == "" || build.Result == "ABORTED" {
+			continue
+		}
+		if last != "" && build.Result != last {
+			result.flips++
+		}
+		last = build.Result
+		result.builds++
+	}
+	return result
+}
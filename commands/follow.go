@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bndr/gojenkins"
+	"github.com/fatih/color"
+	"github.com/mre/riffraff/job"
+)
+
+// jobColors are cycled through to tell the jobs apart in the interleaved output
+var jobColors = []color.Attribute{
+	color.FgCyan,
+	color.FgMagenta,
+	color.FgBlue,
+	color.FgYellow,
+	color.FgGreen,
+	color.FgRed,
+}
+
+type Follow struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	// output is "text" or "ndjson" for one JSON object per line
+	output string
+	// resume continues where a previous, interrupted follow of the same build stopped
+	resume bool
+	// stripANSI removes ANSI escape sequences embedded in the console output
+	stripANSI bool
+}
+
+func NewFollow(jenkins *gojenkins.Jenkins, regex string, output string, resume, stripANSI bool) *Follow {
+	return &Follow{jenkins, regex, output, resume, stripANSI}
+}
+
+func (f Follow) Exec() error {
+	jobs, err := job.FindMatchingJobs(f.jenkins, f.regex)
+	if err != nil {
+		return err
+	}
+
+	forEach(len(jobs), func(i int) {
+		prefix := color.New(jobColors[i%len(jobColors)]).Sprint(jobs[i].Name)
+		if err := f.follow(jobs[i], prefix); err != nil {
+			out.Printf("%v: cannot follow logs: %v\n", prefix, err)
+		}
+	})
+	return nil
+}
+
+// follow streams the console output of the last build of a job
+// until the build is finished
+func (f Follow) follow(job gojenkins.InnerJob, prefix string) error {
+	jenkinsJob, err := f.jenkins.GetJob(job.Name)
+	if err != nil {
+		return err
+	}
+	lastBuild, err := jenkinsJob.GetLastBuild()
+	if err != nil {
+		return err
+	}
+	emit := func(line string) {
+		if f.stripANSI {
+			line = stripANSI(line)
+		}
+		if f.output == "ndjson" {
+			printLogLine(job.Name, lastBuild.GetBuildNumber(), line)
+		} else {
+			out.Printf("%v: %v\n", prefix, line)
+		}
+	}
+	if progress, ok := estimatedProgress(lastBuild); ok && lastBuild.Raw.Building && f.output != "ndjson" {
+		out.Printf("%v: following build %v, %v\n", prefix, lastBuild.GetBuildNumber(), progressText(progress))
+	}
+
+	var offset int64
+	key := offsetKey(job.Name, lastBuild.GetBuildNumber())
+	if f.resume {
+		offset = loadOffset(key)
+	}
+	var pending string
+	err = poll(func() (bool, error) {
+		text, next, more, err := progressiveText(lastBuild, offset)
+		if err != nil && isTransient(err) {
+			// The offset only moves on success, so the next poll resumes where the stream broke off
+			fmt.Fprintf(os.Stderr, "%v: connection lost (%v), resuming at byte %v\n", prefix, err, offset)
+			return false, err
+		}
+		if err != nil {
+			return true, err
+		}
+		offset = next
+
+		// Only emit complete lines and keep the rest for the next chunk
+		chunk := strings.Split(pending+text, "\n")
+		pending = chunk[len(chunk)-1]
+		for _, line := range chunk[:len(chunk)-1] {
+			emit(line)
+		}
+
+		// Remember the offset for --resume, the pending line will be fetched again.
+		// This is best effort, without it a resumed follow starts from the beginning.
+		if more {
+			_ = saveOffset(key, offset-int64(len(pending)))
+		} else {
+			_ = saveOffset(key, 0)
+		}
+		return !more, nil
+	})
+	if pending != "" {
+		emit(pending)
+	}
+	return err
+}
+
+// progressiveText fetches the console output of a build starting at the given byte offset.
+// It returns the text, the offset to continue from and whether more data is expected.
+func progressiveText(build *gojenkins.Build, offset int64) (string, int64, bool, error) {
+	var text string
+	query := map[string]string{"start": strconv.FormatInt(offset, 10)}
+	resp, err := build.Jenkins.Requester.Get(build.Base+"/logText/progressiveText", &text, query)
+	if err != nil {
+		return "", offset, false, err
+	}
+	if resp.StatusCode != 200 {
+		return "", offset, false, fmt.Errorf("unexpected HTTP status %v", resp.StatusCode)
+	}
+
+	next, err := strconv.ParseInt(resp.Header.Get("X-Text-Size"), 10, 64)
+	if err != nil {
+		next = offset + int64(len(text))
+	}
+	return text, next, resp.Header.Get("X-More-Data") == "true", nil
+}
+
+// isTransient tells whether an error is a network failure which is worth retrying,
+// like a connection reset by a proxy, as opposed to an error answer of Jenkins
+func isTransient(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.As(err, &netErr)
+}
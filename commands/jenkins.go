@@ -0,0 +1,44 @@
+package commands
+
+import "github.com/bndr/gojenkins"
+
+// Jenkins is the part of the Jenkins API used by the logs command.
+// Server implements it for a real Jenkins, tests implement it with a mock.
+type Jenkins interface {
+	GetJob(id string, parentIDs ...string) (*gojenkins.Job, error)
+	// GetBuild also gets the last build, with the number from the job's LastBuild
+	GetBuild(jobName string, number int64) (*gojenkins.Build, error)
+	GetAllJobNames() ([]gojenkins.InnerJob, error)
+	// GetBuildDescriptions lists the numbers and descriptions of all builds of a job, newest first
+	GetBuildDescriptions(job *gojenkins.Job) ([]BuildDescription, error)
+	// GetConsoleOutput returns the console output of a build
+	GetConsoleOutput(jobName string, build *gojenkins.Build) string
+}
+
+// BuildDescription is the description of a build
+type BuildDescription struct {
+	Number      int64  `json:"number"`
+	Description string `json:"description"`
+}
+
+// Server implements Jenkins for a Jenkins server.
+// The methods of gojenkins are used as they are, the others are added here.
+type Server struct {
+	*gojenkins.Jenkins
+}
+
+func (s Server) GetBuildDescriptions(job *gojenkins.Job) ([]BuildDescription, error) {
+	var builds struct {
+		Builds []BuildDescription `json:"allBuilds"`
+	}
+	query := map[string]string{"tree": "allBuilds[number,description]"}
+	if _, err := s.Requester.GetJSON(job.Base, &builds, query); err != nil {
+		return nil, err
+	}
+	return builds.Builds, nil
+}
+
+// GetConsoleOutput returns the console output of a build, from the cache for finished builds
+func (s Server) GetConsoleOutput(jobName string, build *gojenkins.Build) string {
+	return consoleOutput(build)
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
+)
+
+type KillStuck struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	// factor is how many times longer than estimated a build may run before it is aborted
+	factor float64
+	dryRun bool
+}
+
+func NewKillStuck(jenkins *gojenkins.Jenkins, regex string, factor float64, dryRun bool) *KillStuck {
+	return &KillStuck{jenkins, regex, factor, dryRun}
+}
+
+func (k KillStuck) Exec() error {
+	if k.factor < 1 {
+		return fmt.Errorf("the factor must be at least 1, got %v", k.factor)
+	}
+	jobs, err := job.FindMatchingJobs(k.jenkins, k.regex)
+	if err != nil {
+		return err
+	}
+	if !k.dryRun {
+		if err = confirmWide("abort the stuck builds of", len(jobs)); err != nil {
+			return err
+		}
+	}
+
+	forEach(len(jobs), func(i int) {
+		if line := k.check(jobs[i]); line != "" {
+			out.Println(line)
+		}
+	})
+	return nil
+}
+
+// check aborts the running build of a job if it exceeded its estimated duration by the factor.
+// It describes what it did, or returns nothing if the job isn't running.
+func (k KillStuck) check(j gojenkins.InnerJob) string {
+	if job.IsDisabled(j) {
+		return ""
+	}
+	jenkinsJob, err := k.jenkins.GetJob(j.Name)
+	if err != nil {
+		return fmt.Sprintf("%v %v: %v", Unknown, j.Name, err)
+	}
+	build, err := jenkinsJob.GetLastBuild()
+	if err != nil || !build.IsRunning() {
+		return ""
+	}
+
+	elapsed := time.Since(build.GetTimestamp()).Round(time.Second)
+	estimated := millis(build.Raw.EstimatedDuration)
+	if estimated <= 0 {
+		return fmt.Sprintf("%v %v [%v]: left alone, running for %v without an estimate", Running, j.Name, build.GetBuildNumber(), elapsed)
+	}
+	limit := time.Duration(float64(estimated) * k.factor).Round(time.Second)
+	if elapsed <= limit {
+		return fmt.Sprintf("%v %v [%v]: left alone, running for %v of at most %v", Running, j.Name, build.GetBuildNumber(), elapsed, limit)
+	}
+	if k.dryRun {
+		return fmt.Sprintf("%v %v [%v]: would abort, running for %v but estimated %v", Bad, j.Name, build.GetBuildNumber(), elapsed, estimated.Round(time.Second))
+	}
+	if _, err := build.Stop(); err != nil {
+		return fmt.Sprintf("%v %v [%v]: cannot abort: %v", Bad, j.Name, build.GetBuildNumber(), err)
+	}
+	return fmt.Sprintf("%v %v [%v]: aborted, running for %v but estimated %v", Aborted, j.Name, build.GetBuildNumber(), elapsed, estimated.Round(time.Second))
+}
@@ -1,73 +1,197 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/bndr/gojenkins"
-	"github.com/fatih/color"
+	"github.com/skratchdot/open-golang/open"
 )
 
 type Logs struct {
-	jenkins *gojenkins.Jenkins
+	jenkins Jenkins
 	jobName string
-	salt    bool
+	options LogsOptions
 }
 
-func NewLogs(jenkins *gojenkins.Jenkins, jobName string, salt bool) *Logs {
-	return &Logs{jenkins, jobName, salt}
+// LogsOptions control which build's logs are shown and how
+type LogsOptions struct {
+	// Salt only shows the failed salt states
+	Salt bool
+	// Minion restricts the salt states to a single minion
+	Minion string
+	// SaltMaxStates only shows the first failed salt states, 0 shows all of them
+	SaltMaxStates int
+	// MaxAge is the maximum age of the build
+	MaxAge time.Duration
+	// Build is the number or a selector like "lastSuccessful" of the build, defaults to the last build
+	Build string
+	// Description selects the newest build with a description matching this regular expression
+	// instead of the last build
+	Description string
+	// MaxBytes only shows the last bytes of the console output, 0 shows everything
+	MaxBytes int64
+	// Output is the output format, "text" or "ndjson" for one JSON object per line
+	Output string
+	// Open opens the build page in the browser after printing the logs
+	Open bool
+	// ArchiveLogs is a directory to save the full console output to
+	ArchiveLogs string
+	// StripANSI removes ANSI escape sequences embedded in the console output
+	StripANSI bool
 }
 
-func (l Logs) Exec() error {
-	yellow := color.New(color.FgYellow).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-	green := color.New(color.FgGreen).SprintFunc()
+// logLine is a line of console output as printed with --output ndjson
+type logLine struct {
+	Job   string    `json:"job"`
+	Build int64     `json:"build"`
+	Line  string    `json:"line"`
+	Time  time.Time `json:"ts"`
+}
 
-	build, err := l.jenkins.GetJob(l.jobName)
+func NewLogs(jenkins Jenkins, jobName string, options LogsOptions) *Logs {
+	return &Logs{jenkins, jobName, options}
+}
+
+func (l Logs) Exec() error {
+	job, err := l.jenkins.GetJob(l.jobName)
 	if err != nil {
 		return err
 	}
 
-	lastBuild, err := build.GetLastBuild()
-	var result string
+	build, err := l.selectBuild(job)
 	if err != nil {
-		result = fmt.Sprintf("UNKNOWN (%v)", err)
-	} else {
-		result = lastBuild.GetResult()
+		return err
+	}
+	result := build.GetResult()
+	if age := time.Since(build.GetTimestamp()); l.options.MaxAge > 0 && age > l.options.MaxAge {
+		return fmt.Errorf("build %v of %v is %v old, which is older than the maximum age of %v",
+			build.GetBuildNumber(), l.jobName, age.Round(time.Second), l.options.MaxAge)
 	}
 
-	var marker string
-	switch result {
-	case "SUCCESS":
-		marker = green("✓")
-	case "FAILURE":
-		marker = red("✗")
-	default:
-		marker = yellow("?")
+	consoleOutput := l.jenkins.GetConsoleOutput(l.jobName, build)
+	if l.options.StripANSI {
+		consoleOutput = stripANSI(consoleOutput)
 	}
 
-	fmt.Printf("%v %v (%v)\n", marker, l.jobName, lastBuild.GetUrl())
+	if l.options.ArchiveLogs != "" {
+		path, err := archiveLog(l.options.ArchiveLogs, l.jobName, build.GetBuildNumber(), consoleOutput)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Archived the logs to %v\n", path)
+	}
+
+	if l.options.Output == "ndjson" {
+		for _, line := range strings.SplitAfter(consoleOutput, "\n") {
+			if line != "" {
+				printLogLine(l.jobName, build.GetBuildNumber(), strings.TrimSuffix(line, "\n"))
+			}
+		}
+		return nil
+	}
+
+	fmt.Printf("%v %v (%v)\n", resultMarker(result), l.jobName, build.GetUrl())
 
 	fmt.Printf("Jenkins result code: %v\n", result)
-	consoleOutput := lastBuild.GetConsoleOutput()
-	if l.salt {
-		for _, stateOutput := range getFailedSaltStates(consoleOutput) {
+	if l.options.Salt {
+		if l.options.Minion != "" {
+			if consoleOutput, err = getSaltMinionOutput(consoleOutput, l.options.Minion); err != nil {
+				return err
+			}
+		}
+		failedStates := getFailedSaltStates(consoleOutput)
+		// The first failures are usually the root cause of the others
+		more := len(failedStates) - l.options.SaltMaxStates
+		if l.options.SaltMaxStates > 0 && more > 0 {
+			failedStates = failedStates[:l.options.SaltMaxStates]
+		}
+		for _, stateOutput := range failedStates {
 			fmt.Println(stateOutput)
 		}
+		if l.options.SaltMaxStates > 0 && more > 0 {
+			fmt.Printf("[... %v more failed states omitted, use --salt-max-states 0 to show all ...]\n", more)
+		}
+		fmt.Println(getSaltSummary(consoleOutput))
 	} else {
-		fmt.Printf(consoleOutput)
+		if omitted := int64(len(consoleOutput)) - l.options.MaxBytes; l.options.MaxBytes > 0 && omitted > 0 {
+			omitted = lineStart(consoleOutput, omitted)
+			fmt.Printf("[... %v bytes omitted, use --max-output-bytes 0 to show everything ...]\n", omitted)
+			consoleOutput = consoleOutput[omitted:]
+		}
+		fmt.Print(consoleOutput)
+	}
+	fmt.Println(consoleTextURL(build.GetUrl()))
+	if l.options.Open {
+		return open.Run(build.GetUrl())
 	}
-	fmt.Printf("%v/consoleText\n", lastBuild.GetUrl())
 	return nil
 }
 
-func getFailedSaltStates(output string) []string {
-	saltStates := strings.Split(output, "----------")
-	var failedStates []string
-	for _, state := range saltStates {
-		if strings.Contains(state, "Result: False") {
-			failedStates = append(failedStates, state)
+// selectBuild returns the last build of a job,
+// or the newest one with a matching description
+func (l Logs) selectBuild(job *gojenkins.Job) (*gojenkins.Build, error) {
+	if l.options.Description == "" {
+		number, err := selectBuildNumber(job, l.options.Build)
+		if err != nil {
+			return nil, err
 		}
+		return l.jenkins.GetBuild(l.jobName, number)
+	}
+
+	pattern, err := regexp.Compile(l.options.Description)
+	if err != nil {
+		return nil, err
+	}
+	builds, err := l.jenkins.GetBuildDescriptions(job)
+	if err != nil {
+		return nil, err
+	}
+	for _, build := range builds {
+		if pattern.MatchString(build.Description) {
+			return l.jenkins.GetBuild(l.jobName, build.Number)
+		}
+	}
+	return nil, fmt.Errorf("no build of %v has a description matching %q", l.jobName, l.options.Description)
+}
+
+// lineStart moves an offset into the output forward to the start of the next line,
+// or to the next character if there is no further line, so a cut doesn't garble the first line
+func lineStart(output string, offset int64) int64 {
+	if offset <= 0 || output[offset-1] == '\n' {
+		return offset
+	}
+	if i := strings.IndexByte(output[offset:], '\n'); i >= 0 && offset+int64(i)+1 < int64(len(output)) {
+		return offset + int64(i) + 1
+	}
+	for offset < int64(len(output)) && !utf8.RuneStart(output[offset]) {
+		offset++
+	}
+	return offset
+}
+
+// printLogLine prints a line of console output as a JSON object.
+// It is safe to call from multiple goroutines.
+func printLogLine(jobName string, build int64, line string) {
+	// Marshaling strings can't fail
+	data, _ := json.Marshal(logLine{jobName, build, line, time.Now()})
+	out.Println(string(data))
+}
+
+// consoleTextURL returns the URL of the plain text console output of a build,
+// no matter if the build URL has a trailing slash or not
+func consoleTextURL(buildURL string) string {
+	u, err := url.Parse(buildURL)
+	if err != nil {
+		return strings.TrimSuffix(buildURL, "/") + "/consoleText"
 	}
-	return failedStates
+	u.Path = path.Join(u.Path, "consoleText")
+	return u.String()
 }
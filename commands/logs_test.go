@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+// mockJenkins serves a single job with the given builds and their console output
+type mockJenkins struct {
+	job     *gojenkins.JobResponse
+	builds  map[int64]*gojenkins.BuildResponse
+	console map[int64]string
+	// descriptions are returned by GetBuildDescriptions, newest first
+	descriptions []BuildDescription
+}
+
+func (m mockJenkins) GetJob(id string, parentIDs ...string) (*gojenkins.Job, error) {
+	return &gojenkins.Job{Raw: m.job, Base: "/job/" + id}, nil
+}
+
+func (m mockJenkins) GetBuild(jobName string, number int64) (*gojenkins.Build, error) {
+	build, ok := m.builds[number]
+	if !ok {
+		return nil, fmt.Errorf("404")
+	}
+	return &gojenkins.Build{Raw: build, Base: fmt.Sprintf("/job/%v/%v", jobName, number)}, nil
+}
+
+func (m mockJenkins) GetAllJobNames() ([]gojenkins.InnerJob, error) {
+	return []gojenkins.InnerJob{{Name: m.job.Name, Url: m.job.URL}}, nil
+}
+
+func (m mockJenkins) GetBuildDescriptions(job *gojenkins.Job) ([]BuildDescription, error) {
+	return m.descriptions, nil
+}
+
+func (m mockJenkins) GetConsoleOutput(jobName string, build *gojenkins.Build) string {
+	return m.console[build.GetBuildNumber()]
+}
+
+func newMockJenkins() mockJenkins {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	return mockJenkins{
+		job: &gojenkins.JobResponse{
+			Name:                "deploy",
+			URL:                 "https://ci.example.com/job/deploy/",
+			LastBuild:           gojenkins.JobBuild{Number: 7},
+			LastSuccessfulBuild: gojenkins.JobBuild{Number: 5},
+		},
+		builds: map[int64]*gojenkins.BuildResponse{
+			5: {Number: 5, Result: "SUCCESS", Timestamp: now, URL: "https://ci.example.com/job/deploy/5/"},
+			6: {Number: 6, Result: "FAILURE", Timestamp: now, URL: "https://ci.example.com/job/deploy/6/"},
+			7: {Number: 7, Result: "FAILURE", Timestamp: now - int64(48*time.Hour/time.Millisecond), URL: "https://ci.example.com/job/deploy/7/"},
+		},
+		console: map[int64]string{
+			5: "build 5\nFinished: SUCCESS\n",
+			6: "build 6\nFinished: FAILURE\n",
+			7: "build 7\nFinished: FAILURE\n",
+		},
+		descriptions: []BuildDescription{{7, "release 1.3"}, {6, "release 1.2"}, {5, "release 1.1"}},
+	}
+}
+
+// captureStdout returns everything f prints to stdout
+func captureStdout(t *testing.T, f func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = f()
+	os.Stdout = stdout
+	w.Close()
+	output, readErr := ioutil.ReadAll(r)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	return string(output), err
+}
+
+func TestLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		options LogsOptions
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "last build",
+			want: []string{"deploy (https://ci.example.com/job/deploy/7/)", "build 7\n", "https://ci.example.com/job/deploy/7/consoleText"},
+		},
+		{
+			name:    "selector",
+			options: LogsOptions{Build: "lastSuccessful"},
+			want:    []string{"Jenkins result code: SUCCESS", "build 5\n"},
+		},
+		{
+			name:    "build number",
+			options: LogsOptions{Build: "6"},
+			want:    []string{"build 6\n"},
+		},
+		{
+			name:    "description",
+			options: LogsOptions{Description: `1\.2$`},
+			want:    []string{"build 6\n"},
+		},
+		{
+			name:    "no matching description",
+			options: LogsOptions{Description: "hotfix"},
+			wantErr: `no build of deploy has a description matching "hotfix"`,
+		},
+		{
+			name:    "too old",
+			options: LogsOptions{MaxAge: time.Hour},
+			wantErr: "older than the maximum age of 1h0m0s",
+		},
+		{
+			name:    "max bytes",
+			options: LogsOptions{MaxBytes: 18},
+			want:    []string{"[... 8 bytes omitted", "\nFinished: FAILURE\n"},
+		},
+		{
+			name:    "max bytes within a line",
+			options: LogsOptions{MaxBytes: 22},
+			want:    []string{"[... 8 bytes omitted", "\nFinished: FAILURE\n"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := captureStdout(t, NewLogs(newMockJenkins(), "deploy", test.options).Exec)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output %q doesn't contain %q", output, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLineStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		offset int64
+		want   int64
+	}{
+		{"start of output", "abc\ndef\n", 0, 0},
+		{"start of a line", "abc\ndef\n", 4, 4},
+		{"within a line", "abc\ndef\n", 2, 4},
+		{"within the last line", "abc\ndef", 5, 5},
+		{"within the last line before its newline", "abc\ndef\n", 5, 5},
+		{"within a character", "abc\nd€f", 6, 8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := lineStart(test.output, test.offset); got != test.want {
+				t.Errorf("lineStart(%q, %v) = %v, want %v", test.output, test.offset, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package commands
+
+import "github.com/fatih/color"
+
+// Markers shared by all commands to visualize the state of jobs and nodes.
+// Their colors depend on the theme.
+var (
+	Good      string
+	Bad       string
+	Unknown   string
+	Running   string
+	Disabled  string
+	Forbidden string
+	Unstable  string
+	Aborted   string
+)
+
+// theme defines the colors of the markers
+type theme struct {
+	good, bad, unknown, running, disabled, forbidden, unstable, aborted color.Attribute
+}
+
+var themes = map[string]theme{
+	"dark":  {color.FgGreen, color.FgRed, color.FgYellow, color.FgGreen, color.FgHiBlack, color.FgRed, color.FgYellow, color.FgHiBlack},
+	"light": {color.FgGreen, color.FgRed, color.FgMagenta, color.FgBlue, color.FgBlack, color.FgRed, color.FgYellow, color.FgHiBlack},
+	// mono disables colors altogether and only uses symbols
+	"mono": {},
+}
+
+func init() {
+	SetTheme("dark")
+}
+
+// SetTheme changes the colors of the markers.
+// Unknown themes fall back to the default dark theme.
+func SetTheme(name string) {
+	t, ok := themes[name]
+	if !ok {
+		t = themes["dark"]
+	}
+	color.NoColor = color.NoColor || name == "mono"
+
+	Good = color.New(t.good).Sprint("✓")
+	Bad = color.New(t.bad).Sprint("✗")
+	Unknown = color.New(t.unknown).Sprint("?")
+	Running = color.New(t.running).Sprint("↻")
+	Disabled = color.New(t.disabled).Sprint("⊘")
+	Forbidden = color.New(t.forbidden).Sprint("⊗")
+	Unstable = color.New(t.unstable).Sprint("!")
+	Aborted = color.New(t.aborted).Sprint("∅")
+}
+
+// resultMarker returns the marker for a Jenkins build result
+func resultMarker(result string) string {
+	switch result {
+	case "RUNNING":
+		return Running
+	case "SUCCESS":
+		return Good
+	case "FAILURE":
+		return Bad
+	case "UNSTABLE":
+		return Unstable
+	case "ABORTED":
+		return Aborted
+	case "DISABLED":
+		return Disabled
+	case "FORBIDDEN":
+		return Forbidden
+	}
+	return Unknown
+}
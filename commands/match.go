@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
+)
+
+type Match struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	// kind restricts the matches to "job", "folder" or "all"
+	kind string
+}
+
+func NewMatch(jenkins *gojenkins.Jenkins, regex, kind string) *Match {
+	return &Match{jenkins, regex, kind}
+}
+
+func (m Match) Exec() error {
+	jobs, err := job.FindMatchingJobs(m.jenkins, m.regex)
+	if err != nil {
+		return err
+	}
+	jobs = job.OfType(jobs, m.kind)
+
+	for _, job := range jobs {
+		printEntry(job.Name)
+	}
+	printCount("%v jobs match %q", len(jobs), m.regex)
+	return nil
+}
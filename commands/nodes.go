@@ -2,19 +2,42 @@ package commands
 
 import (
 	"fmt"
-	"sync"
+	"sort"
+	"time"
 
 	"github.com/bndr/gojenkins"
-	"github.com/fatih/color"
 )
 
 type Nodes struct {
 	jenkins *gojenkins.Jenkins
+	timeout time.Duration
+	quiet   bool
+	// groupByLabel prints the nodes grouped by their primary label
+	groupByLabel bool
 }
 
-func NewNodes(jenkins *gojenkins.Jenkins) *Nodes {
+// nodeStatus is the buffered result of polling a single node
+type nodeStatus struct {
+	name   string
+	line   string
+	online bool
+	// label is only set when grouping by label
+	label string
+}
+
+// nodeLabels are the labels assigned to a node, which aren't part of gojenkins' NodeResponse
+type nodeLabels struct {
+	AssignedLabels []struct {
+		Name string `json:"name"`
+	} `json:"assignedLabels"`
+}
+
+func NewNodes(jenkins *gojenkins.Jenkins, timeout time.Duration, quiet, groupByLabel bool) *Nodes {
 	return &Nodes{
 		jenkins,
+		timeout,
+		quiet,
+		groupByLabel,
 	}
 }
 
@@ -24,35 +47,117 @@ func (n Nodes) Exec() error {
 		return err
 	}
 
-	var waitGroup sync.WaitGroup
-	waitGroup.Add(len(nodes))
-	defer waitGroup.Wait()
-	for _, node := range nodes {
-		go printNodeStatus(&waitGroup, *node)
+	// Buffer all results and print them once all nodes are polled
+	// to avoid interleaved output
+	results := make([]nodeStatus, len(nodes))
+	forEach(len(nodes), func(i int) {
+		results[i] = n.getNodeStatusWithTimeout(*nodes[i])
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].label != results[j].label {
+			return results[i].label < results[j].label
+		}
+		return results[i].name < results[j].name
+	})
+	if n.groupByLabel {
+		n.printGroups(results)
+		return nil
+	}
+	for _, result := range results {
+		if n.quiet && result.online {
+			continue
+		}
+		fmt.Println(result.line)
 	}
 	return nil
 }
 
-func printNodeStatus(waitGroup *sync.WaitGroup, node gojenkins.Node) error {
-	defer waitGroup.Done()
+// getNodeStatusWithTimeout reports a node as unknown if polling it takes too long,
+// so a single unresponsive agent doesn't stall the whole command
+func (n Nodes) getNodeStatusWithTimeout(node gojenkins.Node) nodeStatus {
+	if n.timeout <= 0 {
+		return n.getNodeStatus(node)
+	}
+
+	result := make(chan nodeStatus, 1)
+	go func() {
+		result <- n.getNodeStatus(node)
+	}()
+	select {
+	case status := <-result:
+		return status
+	case <-time.After(n.timeout):
+		name := node.GetName()
+		status := nodeStatus{name, fmt.Sprintf("%v %v: UNKNOWN (timeout)", Unknown, name), false, ""}
+		if n.groupByLabel {
+			status.label = "unknown"
+		}
+		return status
+	}
+}
+
+// getNodeStatus polls a node, and gets its primary label when grouping by label
+func (n Nodes) getNodeStatus(node gojenkins.Node) nodeStatus {
+	status := getNodeStatus(node)
+	if n.groupByLabel {
+		status.label = primaryLabel(node)
+	}
+	return status
+}
+
+// primaryLabel returns the first label assigned to a node besides its own name,
+// which Jenkins always adds as a label
+func primaryLabel(node gojenkins.Node) string {
+	var labels nodeLabels
+	query := map[string]string{"tree": "assignedLabels[name]"}
+	if _, err := node.Jenkins.Requester.GetJSON(node.Base, &labels, query); err != nil {
+		return "unknown"
+	}
+	for _, label := range labels.AssignedLabels {
+		if label.Name != node.GetName() {
+			return label.Name
+		}
+	}
+	return "none"
+}
+
+// printGroups prints the nodes beneath a header per label with the number of online and offline nodes
+func (n Nodes) printGroups(results []nodeStatus) {
+	for start := 0; start < len(results); {
+		end := start
+		online := 0
+		for end < len(results) && results[end].label == results[start].label {
+			if results[end].online {
+				online++
+			}
+			end++
+		}
+		fmt.Printf("%v (%v online, %v offline)\n", results[start].label, online, end-start-online)
+		for _, result := range results[start:end] {
+			if !n.quiet || !result.online {
+				fmt.Printf("  %v\n", result.line)
+			}
+		}
+		start = end
+	}
+}
+
+func getNodeStatus(node gojenkins.Node) nodeStatus {
+	name := node.GetName()
 	// Fetch Node Data
 	_, err := node.Poll()
 	if err != nil {
-		return err
+		return nodeStatus{name, fmt.Sprintf("%v %v: UNKNOWN (%v)", Unknown, name, err), false, ""}
 	}
 
 	online, err := node.IsOnline()
 	if err != nil {
-		return err
+		return nodeStatus{name, fmt.Sprintf("%v %v: UNKNOWN (%v)", Unknown, name, err), false, ""}
 	}
 
-	red := color.New(color.FgRed).SprintFunc()
-	green := color.New(color.FgGreen).SprintFunc()
-
 	if online {
-		fmt.Printf("%v %v: Online\n", green("✓"), node.GetName())
-	} else {
-		fmt.Printf("%v %v: Offline\n", red("✗"), node.GetName())
+		return nodeStatus{name, fmt.Sprintf("%v %v: Online", Good, name), true, ""}
 	}
-	return nil
+	return nodeStatus{name, fmt.Sprintf("%v %v: Offline", Bad, name), false, ""}
 }
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// webhookMessage is compatible with Slack's incoming webhooks.
+// Other receivers can use the structured list of jobs.
+type webhookMessage struct {
+	Text string      `json:"text"`
+	Jobs []JobStatus `json:"jobs"`
+}
+
+// notifyWebhook posts the failing jobs to a webhook.
+// It uses the client configured for Jenkins, so the proxy settings apply to it as well.
+func notifyWebhook(client *http.Client, url string, failing []JobStatus) error {
+	lines := []string{fmt.Sprintf("%v failing Jenkins jobs:", len(failing))}
+	for _, status := range failing {
+		lines = append(lines, fmt.Sprintf("• <%v|%v>: %v", status.URL, status.Name, status.Result))
+	}
+	payload, err := json.Marshal(webhookMessage{strings.Join(lines, "\n"), failing})
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered with HTTP %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// offsetsMu serializes the updates of the offsets file by concurrently followed jobs
+var offsetsMu sync.Mutex
+
+// offsetKey identifies the console output of a build in the offsets file
+func offsetKey(jobName string, number int64) string {
+	return fmt.Sprintf("%v#%v", jobName, number)
+}
+
+// offsetsFile is the file the console offsets of followed builds are stored in
+func offsetsFile() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "offsets.json"), nil
+}
+
+// readOffsets reads the console offsets by build, a missing file has no offsets
+func readOffsets() (map[string]int64, error) {
+	offsets := map[string]int64{}
+	path, err := offsetsFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return offsets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &offsets); err != nil {
+		return nil, fmt.Errorf("cannot read %v: %v", path, err)
+	}
+	return offsets, nil
+}
+
+// loadOffset returns the console offset up to which a build was followed before
+func loadOffset(key string) int64 {
+	offsetsMu.Lock()
+	defer offsetsMu.Unlock()
+	offsets, err := readOffsets()
+	if err != nil {
+		return 0
+	}
+	return offsets[key]
+}
+
+// saveOffset remembers up to which console offset a build was followed.
+// An offset of 0 forgets the build, e.g. once it is finished.
+func saveOffset(key string, offset int64) error {
+	offsetsMu.Lock()
+	defer offsetsMu.Unlock()
+	offsets, err := readOffsets()
+	if err != nil {
+		return err
+	}
+	if offset == 0 {
+		delete(offsets, key)
+	} else {
+		offsets[key] = offset
+	}
+
+	path, err := offsetsFile()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
@@ -12,28 +12,65 @@ import (
 type Open struct {
 	jenkins *gojenkins.Jenkins
 	regex   string
+	failing bool
+	// printURLs prints the URLs instead of opening them
+	printURLs bool
 }
 
-func NewOpen(jenkins *gojenkins.Jenkins, regex string) *Open {
+func NewOpen(jenkins *gojenkins.Jenkins, regex string, failing, printURLs bool) *Open {
 	return &Open{
 		jenkins,
 		regex,
+		failing,
+		printURLs,
 	}
 }
 
 func (o Open) Exec() error {
-	jobs, err := job.FindMatchingJobs(o.jenkins, o.regex)
+	urls, err := o.findURLs()
 	if err != nil {
 		return err
 	}
-	if len(jobs) > 3 {
+	if o.printURLs {
+		for _, url := range urls {
+			printEntry(url)
+		}
+		return nil
+	}
+	if len(urls) > 3 {
 		log.Fatalf("More than three jobs match your criteria. This is probably not what you expected. Please narrow down your search\n")
 	}
 
-	for _, job := range jobs {
-		if err = open.Run(job.Url); err != nil {
+	for _, url := range urls {
+		if err = open.Run(url); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// findURLs returns the URLs of all matching jobs, or only of the failing ones
+func (o Open) findURLs() ([]string, error) {
+	var urls []string
+	if !o.failing {
+		jobs, err := job.FindMatchingJobs(o.jenkins, o.regex)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			urls = append(urls, job.Url)
+		}
+		return urls, nil
+	}
+
+	statuses, err := NewStatus(o.jenkins, o.regex, StatusOptions{}).Collect()
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.Result == "FAILURE" {
+			urls = append(urls, status.URL)
+		}
+	}
+	return urls, nil
+}
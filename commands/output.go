@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// ansiEscape matches ANSI escape sequences like colors (CSI) and window titles or links (OSC)
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// stripANSI removes ANSI escape sequences, e.g. colors of build tools in console output
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// entryEnd terminates the entries of plain lists like job names or URLs
+var entryEnd = "\n"
+
+// SetNullDelimited separates the entries of plain lists with NUL bytes instead of newlines,
+// so names with spaces or special characters can be passed to xargs -0 safely
+func SetNullDelimited(null bool) {
+	if null {
+		entryEnd = "\x00"
+	} else {
+		entryEnd = "\n"
+	}
+}
+
+// printEntry prints an entry of a plain list
+func printEntry(entry string) {
+	fmt.Print(entry + entryEnd)
+}
+
+// printCount prints the number of entries after a plain list.
+// The count goes to stderr to keep stdout usable in pipes.
+func printCount(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// printJSON prints v as JSON, indented if pretty is set
+func printJSON(v interface{}, pretty bool) error {
+	var out []byte
+	var err error
+	if pretty {
+		out, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		out, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// orderedObject is a JSON object whose keys are serialized in insertion order
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *orderedObject) set(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteString(":")
+		buf.Write(v)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// out is shared by all goroutines which print while other goroutines are still running
+var out = &lineWriter{w: os.Stdout}
+
+// forEach calls f for every index in 0..n-1 concurrently and waits for all calls to return.
+// Results should be stored by index, so they keep their order without further locking.
+func forEach(n int, f func(i int)) {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// forEachLimit is like forEach, but runs at most limit calls of f at the same time
+func forEachLimit(n, limit int, f func(i int)) {
+	if limit <= 0 {
+		forEach(n, f)
+		return
+	}
+	slots := make(chan struct{}, limit)
+	forEach(n, func(i int) {
+		slots <- struct{}{}
+		defer func() { <-slots }()
+		f(i)
+	})
+}
+
+// lineWriter serializes the output of concurrent goroutines
+// so that lines never interleave
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lineWriter) Printf(format string, a ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, format, a...)
+}
+
+func (l *lineWriter) Println(a ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintln(l.w, a...)
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+const (
+	// minPollInterval keeps us from hammering the server
+	minPollInterval = time.Second
+	// maxPollErrors is the number of consecutive failed polls after which we give up
+	maxPollErrors = 5
+)
+
+var pollInterval = 2 * time.Second
+
+// retriesLeft is the number of retries left for all polls together, negative means unlimited
+var retriesLeft int64 = -1
+
+// SetPollInterval sets the time to wait between two polls of the follow and wait features
+func SetPollInterval(interval time.Duration) {
+	if interval < minPollInterval {
+		interval = minPollInterval
+	}
+	pollInterval = interval
+}
+
+// SetMaxRetries limits the number of retries of all polls together,
+// so an outage doesn't make each of many jobs retry on its own. Negative values remove the limit.
+func SetMaxRetries(n int) {
+	atomic.StoreInt64(&retriesLeft, int64(n))
+}
+
+// takeRetry uses up one retry of the budget and reports whether there was one left
+func takeRetry() bool {
+	for {
+		left := atomic.LoadInt64(&retriesLeft)
+		if left < 0 {
+			return true
+		}
+		if left == 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&retriesLeft, left, left-1) {
+			return true
+		}
+	}
+}
+
+// poll calls f every pollInterval until it is done.
+// Errors of f are treated as transient unless f is also done
+// or they happen maxPollErrors times in a row.
+func poll(f func() (bool, error)) error {
+	failures := 0
+	for {
+		done, err := f()
+		if done {
+			return err
+		}
+		if err != nil {
+			failures++
+			if failures >= maxPollErrors {
+				return err
+			}
+			if !takeRetry() {
+				return fmt.Errorf("%v (no retries left, see --max-retries-total)", err)
+			}
+		} else {
+			failures = 0
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// queueItemResponse is the subset of a queue item we need to follow it into a build
+type queueItemResponse struct {
+	Cancelled  bool   `json:"cancelled"`
+	Why        string `json:"why"`
+	Executable struct {
+		Number int64  `json:"number"`
+		URL    string `json:"url"`
+	} `json:"executable"`
+}
+
+// waitForQueuedBuild polls a queue item until Jenkins assigned a build number to it.
+// Transient errors are retried, a cancelled item is reported as an error.
+func waitForQueuedBuild(jenkins *gojenkins.Jenkins, queueID int64) (int64, error) {
+	var number int64
+	err := poll(func() (bool, error) {
+		var item queueItemResponse
+		resp, err := jenkins.Requester.GetJSON(fmt.Sprintf("/queue/item/%d", queueID), &item, nil)
+		if err != nil {
+			return false, fmt.Errorf("cannot poll queue item %v: %v", queueID, err)
+		}
+		if resp.StatusCode != 200 {
+			return false, fmt.Errorf("cannot poll queue item %v: HTTP %v", queueID, resp.StatusCode)
+		}
+		if item.Cancelled {
+			return true, fmt.Errorf("queue item %v was cancelled", queueID)
+		}
+		// Still pending, e.g. waiting for an executor
+		number = item.Executable.Number
+		return number != 0, nil
+	})
+	return number, err
+}
+
+// waitForBuild polls a build until it is finished
+func waitForBuild(build *gojenkins.Build) error {
+	return poll(func() (bool, error) {
+		status, err := build.Poll()
+		if err != nil {
+			return false, fmt.Errorf("cannot poll build %v: %v", build.GetUrl(), err)
+		}
+		if status != 200 {
+			return false, fmt.Errorf("cannot poll build %v: HTTP %v", build.GetUrl(), status)
+		}
+		return !build.Raw.Building, nil
+	})
+}
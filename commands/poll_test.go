@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pollResult is what the polled function returns on one call
+type pollResult struct {
+	done bool
+	err  error
+}
+
+func TestPoll(t *testing.T) {
+	transient := errors.New("connection reset")
+	tests := []struct {
+		name       string
+		maxRetries int
+		results    []pollResult
+		wantCalls  int
+		wantErr    string
+	}{
+		{
+			name:       "done at once",
+			maxRetries: -1,
+			results:    []pollResult{{true, nil}},
+			wantCalls:  1,
+		},
+		{
+			name:       "transient error then success",
+			maxRetries: -1,
+			results:    []pollResult{{false, nil}, {false, transient}, {true, nil}},
+			wantCalls:  3,
+		},
+		{
+			name:       "final error",
+			maxRetries: -1,
+			results:    []pollResult{{true, errors.New("cancelled")}},
+			wantCalls:  1,
+			wantErr:    "cancelled",
+		},
+		{
+			name:       "too many consecutive errors",
+			maxRetries: -1,
+			results:    []pollResult{{false, transient}, {false, transient}, {false, transient}, {false, transient}, {false, transient}},
+			wantCalls:  maxPollErrors,
+			wantErr:    "connection reset",
+		},
+		{
+			name:       "success resets the consecutive errors",
+			maxRetries: -1,
+			results: []pollResult{{false, transient}, {false, transient}, {false, transient}, {false, transient}, {false, nil},
+				{false, transient}, {false, transient}, {true, nil}},
+			wantCalls: 8,
+		},
+		{
+			name:       "shared budget runs out",
+			maxRetries: 1,
+			results:    []pollResult{{false, transient}, {false, transient}, {true, nil}},
+			wantCalls:  2,
+			wantErr:    "no retries left",
+		},
+		{
+			name:       "no retries at all",
+			maxRetries: 0,
+			results:    []pollResult{{false, transient}, {true, nil}},
+			wantCalls:  1,
+			wantErr:    "no retries left",
+		},
+	}
+
+	interval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() {
+		pollInterval = interval
+		SetMaxRetries(-1)
+	}()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			SetMaxRetries(test.maxRetries)
+			calls := 0
+			err := poll(func() (bool, error) {
+				result := test.results[calls]
+				calls++
+				return result.done, result.err
+			})
+			if calls != test.wantCalls {
+				t.Errorf("got %v calls, want %v", calls, test.wantCalls)
+			}
+			if test.wantErr == "" && err != nil {
+				t.Errorf("got error %v, want none", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("got error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTakeRetry(t *testing.T) {
+	defer SetMaxRetries(-1)
+
+	SetMaxRetries(2)
+	for i, want := range []bool{true, true, false, false} {
+		if got := takeRetry(); got != want {
+			t.Errorf("retry %v: got %v, want %v", i+1, got, want)
+		}
+	}
+
+	SetMaxRetries(-1)
+	for i := 0; i < 100; i++ {
+		if !takeRetry() {
+			t.Fatal("unlimited retries ran out")
+		}
+	}
+}
@@ -2,31 +2,114 @@ package commands
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
 )
 
+// labelPattern extracts the label (or node name) a queue item is waiting for
+// from Jenkins' "why" message, e.g. "Waiting for next available executor on ‘linux’"
+var labelPattern = regexp.MustCompile(`[‘'"]([^’'"]+)[’'"]`)
+
 type Queue struct {
 	jenkins *gojenkins.Jenkins
 	regex   string
 	verbose bool
 	salt    bool
+	// histogram prints how long the matching items have been waiting
+	histogram bool
+}
+
+// queueAgeBuckets are the upper bounds of the histogram buckets, the last one is open
+var queueAgeBuckets = []struct {
+	label string
+	max   time.Duration
+}{
+	{"<1m", time.Minute},
+	{"1-5m", 5 * time.Minute},
+	{"5-30m", 30 * time.Minute},
+	{">30m", 0},
 }
 
-func NewQueue(jenkins *gojenkins.Jenkins, regex string, verbose, salt bool) *Queue {
+func NewQueue(jenkins *gojenkins.Jenkins, regex string, verbose, salt, histogram bool) *Queue {
 	return &Queue{
 		jenkins,
 		regex,
 		verbose,
 		salt,
+		histogram,
 	}
 }
 
 func (q Queue) Exec() error {
+	matcher, err := job.NewMatcher(q.regex)
+	if err != nil {
+		return err
+	}
+
 	queue, err := q.jenkins.GetQueue()
 	if err != nil {
 		return err
 	}
-	fmt.Println(queue.Raw)
+
+	items := queue.Raw.Items
+	// Oldest items are first in line
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].InQueueSince < items[j].InQueueSince
+	})
+
+	// Items compete with all other items for the same label,
+	// not only with the ones matching the regex
+	total := map[string]int{}
+	for _, item := range items {
+		total[queueLabel(item.Why)]++
+	}
+
+	position := map[string]int{}
+	var waiting []time.Duration
+	for _, item := range items {
+		label := queueLabel(item.Why)
+		position[label]++
+		if !matcher.Match(item.Task.Name) {
+			continue
+		}
+		waiting = append(waiting, time.Since(time.Unix(0, item.InQueueSince*int64(time.Millisecond))))
+		fmt.Printf("#%v of %v for label %v: %v (%v)\n", position[label], total[label], label, item.Task.Name, item.Task.URL)
+		if q.verbose {
+			fmt.Printf("  %v\n", item.Why)
+		}
+	}
+	if q.histogram {
+		printQueueHistogram(waiting)
+	}
 	return nil
 }
+
+// printQueueHistogram prints how many queue items have been waiting for how long
+func printQueueHistogram(waiting []time.Duration) {
+	counts := make([]int, len(queueAgeBuckets))
+	for _, age := range waiting {
+		for i, bucket := range queueAgeBuckets {
+			if age < bucket.max || bucket.max == 0 {
+				counts[i]++
+				break
+			}
+		}
+	}
+	fmt.Println("\nWaiting for:")
+	for i, bucket := range queueAgeBuckets {
+		fmt.Printf("  %-6v %3v %v\n", bucket.label, counts[i], strings.Repeat("█", counts[i]))
+	}
+}
+
+// queueLabel returns the label a queue item waits for or "any" if it is unknown
+func queueLabel(why string) string {
+	if match := labelPattern.FindStringSubmatch(why); match != nil {
+		return match[1]
+	}
+	return "any"
+}
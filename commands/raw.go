@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+)
+
+type Raw struct {
+	jenkins *gojenkins.Jenkins
+	method  string
+	path    string
+	data    string
+	headers []string
+}
+
+func NewRaw(jenkins *gojenkins.Jenkins, method, path, data string, headers []string) *Raw {
+	return &Raw{jenkins, method, path, data, headers}
+}
+
+func (r Raw) Exec() error {
+	method := strings.ToUpper(r.method)
+	path := relativePath(r.jenkins.Server, r.path)
+
+	var body io.Reader
+	if r.data != "" {
+		body = strings.NewReader(r.data)
+	}
+	req, err := newRequest(r.jenkins, method, path, body)
+	if err != nil {
+		return err
+	}
+	if r.data != "" && method != "GET" && method != "HEAD" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for _, header := range r.headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid header %q, expected \"Name: value\"", header)
+		}
+		req.Header.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	resp, err := r.jenkins.Requester.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err = io.Copy(os.Stdout, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%v %v failed with HTTP %v", method, path, resp.StatusCode)
+	}
+	return nil
+}
+
+// newRequest creates an authenticated request for a path relative to the Jenkins server.
+// The Requester of gojenkins appends a slash to all endpoints and buffers whole responses in memory,
+// so requests which need the path unchanged or stream the response are built by hand.
+func newRequest(jenkins *gojenkins.Jenkins, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, jenkins.Server+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if auth := jenkins.Requester.BasicAuth; auth != nil {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	if method != "GET" && method != "HEAD" {
+		// Modifying requests need a CSRF crumb on most instances
+		ar := gojenkins.NewAPIRequest(method, path, nil)
+		if err = jenkins.Requester.SetCrumb(ar); err != nil {
+			return nil, err
+		}
+		for name := range ar.Headers {
+			req.Header.Set(name, ar.Headers.Get(name))
+		}
+	}
+	return req, nil
+}
+
+// relativePath turns a path or URL into a path relative to the Jenkins server.
+// URLs and paths copied from the browser include the context path of a Jenkins
+// served under e.g. https://ci.example.com/jenkins, which must not be added twice.
+func relativePath(server, path string) string {
+	path = strings.TrimPrefix(path, server)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if base, err := url.Parse(server); err == nil && base.Path != "" && strings.HasPrefix(path, base.Path+"/") {
+		path = strings.TrimPrefix(path, base.Path)
+	}
+	return path
+}
+
+// jobPath returns the path of a job relative to the Jenkins server, taken from the job's URL.
+// Unlike "/job/"+name, it is escaped and includes the folders the job is in.
+func jobPath(server, jobURL string) (string, error) {
+	u, err := url.Parse(jobURL)
+	if err != nil {
+		return "", err
+	}
+	return relativePath(server, u.EscapedPath()), nil
+}
+
+// escapePath escapes each segment of a path like "/job/my job/build",
+// so names with spaces or "#" survive in URLs built by hand
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
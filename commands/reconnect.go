@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+type Reconnect struct {
+	jenkins *gojenkins.Jenkins
+	name    string
+	// timeout is how long to wait for the agent to come back online, 0 doesn't wait
+	timeout time.Duration
+}
+
+func NewReconnect(jenkins *gojenkins.Jenkins, name string, timeout time.Duration) *Reconnect {
+	return &Reconnect{jenkins, name, timeout}
+}
+
+func (r Reconnect) Exec() error {
+	node, err := r.jenkins.GetNode(r.name)
+	if err != nil {
+		return fmt.Errorf("cannot get node %v: %v", r.name, err)
+	}
+	if !node.Raw.Offline {
+		fmt.Printf("%v %v: Online, nothing to do\n", Good, r.name)
+		return nil
+	}
+
+	// The cause is gone once the agent is relaunched, so report it first
+	cause := node.Raw.OfflineCauseReason
+	if cause == "" {
+		cause = "no cause given"
+	}
+	fmt.Printf("%v %v: Offline (%v)\n", Bad, r.name, cause)
+
+	// Agents marked offline by hand only need to be toggled back
+	if node.Raw.TemporarilyOffline {
+		if _, err = node.ToggleTemporarilyOffline(); err != nil {
+			return fmt.Errorf("cannot bring %v back online: %v", r.name, err)
+		}
+	}
+	if _, err = node.Poll(); err != nil {
+		return err
+	}
+	if node.Raw.Offline {
+		if !node.Raw.LaunchSupported {
+			return fmt.Errorf("%v can't be relaunched from Jenkins, the agent has to connect by itself", r.name)
+		}
+		status, err := node.LaunchNodeBySSH()
+		if err != nil {
+			return err
+		}
+		if status >= 400 {
+			return fmt.Errorf("relaunching %v failed with HTTP %v", r.name, status)
+		}
+	}
+
+	deadline := time.Now().Add(r.timeout)
+	online := false
+	// Waits --poll-interval between checks and uses the retry budget like all other polls
+	err = poll(func() (bool, error) {
+		var err error
+		if online, err = node.IsOnline(); err != nil {
+			return false, err
+		}
+		return online || !time.Now().Before(deadline), nil
+	})
+	if err != nil {
+		return err
+	}
+	if online {
+		fmt.Printf("%v %v: Online\n", Good, r.name)
+		return nil
+	}
+	if r.timeout <= 0 {
+		fmt.Printf("%v %v: Relaunched, not online yet\n", Unknown, r.name)
+		return nil
+	}
+	return fmt.Errorf("%v is still offline after %v (%v)", r.name, r.timeout, node.Raw.OfflineCauseReason)
+}
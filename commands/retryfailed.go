@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/bndr/gojenkins"
+)
+
+type RetryFailed struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	// concurrency is the maximum number of builds triggered at the same time, 0 for no limit
+	concurrency int
+	confirmed   bool
+}
+
+func NewRetryFailed(jenkins *gojenkins.Jenkins, regex string, concurrency int, confirmed bool) *RetryFailed {
+	return &RetryFailed{jenkins, regex, concurrency, confirmed}
+}
+
+func (r RetryFailed) Exec() error {
+	statuses, err := NewStatus(r.jenkins, r.regex, StatusOptions{}).Collect()
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, status := range statuses {
+		if status.Result == "FAILURE" {
+			failed = append(failed, status.Name)
+		}
+	}
+	if len(failed) == 0 {
+		fmt.Printf("%v No failed jobs match %q\n", Good, r.regex)
+		return nil
+	}
+	if !r.confirmed {
+		for _, name := range failed {
+			fmt.Printf("%v %v\n", Bad, name)
+		}
+		return fmt.Errorf("this would retrigger the %v failed jobs above, please confirm with --yes", len(failed))
+	}
+	if err = confirmWide("retrigger", len(failed)); err != nil {
+		return err
+	}
+
+	var requeued int32
+	forEachLimit(len(failed), r.concurrency, func(i int) {
+		if err := r.retry(failed[i]); err != nil {
+			out.Printf("%v Retrying %v failed: %v\n", Bad, failed[i], err)
+			return
+		}
+		atomic.AddInt32(&requeued, 1)
+		out.Printf("%v Requeued %v\n", Running, failed[i])
+	})
+
+	fmt.Printf("Requeued %v of %v failed jobs\n", requeued, len(failed))
+	if int(requeued) < len(failed) {
+		return fmt.Errorf("%v jobs could not be requeued", len(failed)-int(requeued))
+	}
+	return nil
+}
+
+// retry triggers a job again with the parameters of its last build
+func (r RetryFailed) retry(jobName string) error {
+	job, err := r.jenkins.GetJob(jobName)
+	if err != nil {
+		return err
+	}
+	build, err := job.GetLastBuild()
+	if err != nil {
+		return err
+	}
+
+	var params map[string]string
+	for _, param := range build.GetParameters() {
+		if params == nil {
+			params = map[string]string{}
+		}
+		params[param.Name] = param.Value
+	}
+	throttleTrigger()
+	_, err = r.jenkins.BuildJob(jobName, params)
+	return err
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	saltSucceededPattern = regexp.MustCompile(`(?m)^Succeeded:\s*(\d+)(?:\s*\(changed=(\d+)\))?`)
+	saltFailedPattern    = regexp.MustCompile(`(?m)^Failed:\s*(\d+)`)
+	// saltMinionPattern matches the header line of a minion's output, e.g. "web01.example.com:"
+	saltMinionPattern = regexp.MustCompile(`(?m)^([^\s:#]\S*):[ \t]*$`)
+)
+
+// saltSummary holds the number of states of a salt run by outcome
+type saltSummary struct {
+	failed    int
+	changed   int
+	succeeded int
+}
+
+func (s saltSummary) String() string {
+	return fmt.Sprintf("Summary: %v failed, %v changed, %v succeeded", s.failed, s.changed, s.succeeded)
+}
+
+func getFailedSaltStates(output string) []string {
+	saltStates := strings.Split(output, "----------")
+	var failedStates []string
+	for _, state := range saltStates {
+		if strings.Contains(state, "Result: False") {
+			failedStates = append(failedStates, state)
+		}
+	}
+	return failedStates
+}
+
+// getSaltSummary adds up the summary blocks of all minions in a highstate output.
+// If there are none, the states are counted instead.
+func getSaltSummary(output string) saltSummary {
+	var summary saltSummary
+	succeeded := saltSucceededPattern.FindAllStringSubmatch(output, -1)
+	failed := saltFailedPattern.FindAllStringSubmatch(output, -1)
+	if len(succeeded) == 0 && len(failed) == 0 {
+		summary.failed = strings.Count(output, "Result: False")
+		summary.succeeded = strings.Count(output, "Result: True")
+		return summary
+	}
+
+	for _, match := range succeeded {
+		summary.succeeded += atoi(match[1])
+		summary.changed += atoi(match[2])
+	}
+	for _, match := range failed {
+		summary.failed += atoi(match[1])
+	}
+	return summary
+}
+
+// atoi converts a number matched by a regular expression, treating an empty match as zero
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// getSaltMinionOutput returns the part of the output belonging to the given minion
+func getSaltMinionOutput(output, minion string) (string, error) {
+	headers := saltMinionPattern.FindAllStringSubmatchIndex(output, -1)
+	var minions []string
+	for i, header := range headers {
+		name := output[header[2]:header[3]]
+		minions = append(minions, name)
+		if name != minion {
+			continue
+		}
+		end := len(output)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		return output[header[1]:end], nil
+	}
+	return "", fmt.Errorf("no output for minion %v, found: %v", minion, strings.Join(minions, ", "))
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+)
+
+type Scan struct {
+	jenkins *gojenkins.Jenkins
+	jobName string
+}
+
+func NewScan(jenkins *gojenkins.Jenkins, jobName string) *Scan {
+	return &Scan{jenkins, jobName}
+}
+
+func (s Scan) Exec() error {
+	job, err := s.jenkins.GetJob(s.jobName)
+	if err != nil {
+		return err
+	}
+	// Branch indexing is only available for multibranch projects and organization folders
+	if !strings.HasSuffix(job.Raw.Class, "MultiBranchProject") && !strings.HasSuffix(job.Raw.Class, "OrganizationFolder") {
+		return fmt.Errorf("%v is not a multibranch project (%v)", s.jobName, job.Raw.Class)
+	}
+
+	// Building a multibranch project triggers a scan
+	resp, err := s.jenkins.Requester.Post(job.Base+"/build", nil, nil, map[string]string{"delay": "0"})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("triggering scan for %v failed with HTTP %v", s.jobName, resp.StatusCode)
+	}
+	fmt.Printf("Queued scan for %v (%v)\n", s.jobName, job.Raw.URL)
+	return nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bndr/gojenkins"
+)
+
+type Stages struct {
+	jenkins *gojenkins.Jenkins
+	jobName string
+	build   int64
+}
+
+func NewStages(jenkins *gojenkins.Jenkins, jobName string, build int64) *Stages {
+	return &Stages{jenkins, jobName, build}
+}
+
+func (s Stages) Exec() error {
+	job, err := s.jenkins.GetJob(s.jobName)
+	if err != nil {
+		return err
+	}
+	number := s.build
+	if number == 0 {
+		number = job.Raw.LastBuild.Number
+	}
+
+	// gojenkins' GetPipelineRun appends api/json to the wfapi URL, so query it directly
+	var run gojenkins.PipelineRun
+	resp, err := s.jenkins.Requester.Get(fmt.Sprintf("%v/%v/wfapi/describe", job.Base, number), &run, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("cannot get stages of %v [%v]: HTTP %v (is it a Pipeline job?)", s.jobName, number, resp.StatusCode)
+	}
+
+	fmt.Printf("%v %v [%v]: %v (%v)\n", stageMarker(run.Status), s.jobName, number, run.Status, millis(run.Duration))
+	for _, stage := range run.Stages {
+		fmt.Printf("  %v %v: %v (%v)\n", stageMarker(stage.Status), stage.Name, stage.Status, millis(stage.Duration))
+	}
+	return nil
+}
+
+// stageMarker returns the marker for the status of a pipeline run or stage
+func stageMarker(status string) string {
+	switch status {
+	case "IN_PROGRESS":
+		return Running
+	case "FAILED":
+		return Bad
+	}
+	return resultMarker(status)
+}
+
+// millis converts a duration in milliseconds as used by the Jenkins API
+func millis(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+)
+
+type Stats struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+	output  string
+}
+
+// statsSummary holds the aggregate numbers of an instance
+type statsSummary struct {
+	// Jobs counts the jobs by result, including a total
+	Jobs  map[string]int `json:"jobs"`
+	Nodes struct {
+		Online  int `json:"online"`
+		Offline int `json:"offline"`
+	} `json:"nodes"`
+	Queue int `json:"queue"`
+}
+
+func NewStats(jenkins *gojenkins.Jenkins, regex, output string) *Stats {
+	return &Stats{jenkins, regex, output}
+}
+
+func (s Stats) Exec() error {
+	var summary statsSummary
+
+	statuses, err := NewStatus(s.jenkins, s.regex, StatusOptions{}).Collect()
+	if err != nil {
+		return err
+	}
+	summary.Jobs = countResults(statuses)
+
+	// The node list already contains the online state, no need to poll each node
+	nodes, err := s.jenkins.GetAllNodes()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node.Raw.Offline {
+			summary.Nodes.Offline++
+		} else {
+			summary.Nodes.Online++
+		}
+	}
+
+	queue, err := s.jenkins.GetQueue()
+	if err != nil {
+		return err
+	}
+	summary.Queue = len(queue.Raw.Items)
+
+	if s.output == "json" {
+		return printJSON(summary, false)
+	}
+
+	var results []string
+	for result := range summary.Jobs {
+		if result != "total" {
+			results = append(results, fmt.Sprintf("%v %v", summary.Jobs[result], result))
+		}
+	}
+	sort.Strings(results)
+	fmt.Printf("Jobs:  %v total (%v)\n", summary.Jobs["total"], strings.Join(results, ", "))
+	fmt.Printf("Nodes: %v online, %v offline\n", summary.Nodes.Online, summary.Nodes.Offline)
+	fmt.Printf("Queue: %v items\n", summary.Queue)
+	return nil
+}
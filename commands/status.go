@@ -1,74 +1,729 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
-	"sync"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/bndr/gojenkins"
-	"github.com/fatih/color"
 	"github.com/mre/riffraff/job"
 )
 
 type Status struct {
 	jenkins *gojenkins.Jenkins
 	regex   string
+	options StatusOptions
 }
 
-func NewStatus(jenkins *gojenkins.Jenkins, regex string) *Status {
-	return &Status{jenkins, regex}
+// StatusOptions control which jobs the status command shows and how
+type StatusOptions struct {
+	// Output is the output format, "text", "json", "compact" or "summary"
+	Output          string
+	Pretty          bool
+	IncludeDisabled bool
+	Fields          []string
+	// Repeat the status query this many times, waiting the poll interval in between,
+	// and report how often each job changed its state
+	Repeat int
+	// NotifyWebhook receives a summary of the failing jobs if there are any
+	NotifyWebhook string
+	// Annotate shows up to this many names of failed tests for each job
+	Annotate int
+	// Assert is checked against the number of jobs by result, e.g. "failure==0"
+	Assert string
+	// Quiet only prints jobs with problems
+	Quiet bool
+	// WatchUntil repeats the status query every poll interval until all jobs succeeded ("success"),
+	// a job failed ("failure") or no job is running anymore ("complete")
+	WatchUntil string
+	// CacheTTL reuses job statuses from previous runs which are younger than this
+	CacheTTL time.Duration
+	// Refresh ignores the cached job statuses, but still updates the cache
+	Refresh bool
+	// OnlyBuilding only prints jobs with a running build
+	OnlyBuilding bool
+	// Cause only prints jobs whose last build was started this way, e.g. "timer"
+	Cause string
+	// CauseUser only prints jobs whose last build was started by this user
+	CauseUser string
+	// ArchiveLogs is a directory to save the console output of failed jobs to
+	ArchiveLogs string
+	// Sort orders the jobs by "name" or by "duration" of the last build, longest first
+	Sort string
+	// StripPrefix is removed from the job names in the text output, but not from URLs or JSON.
+	// "auto" strips the longest common prefix of all jobs up to a separator like "-".
+	StripPrefix string
+	// MaxNameWidth shortens longer job names in the text output in the middle, 0 for no limit
+	MaxNameWidth int
+	// FailOnUnknown fails with an UnknownResultError if the result of any job couldn't be determined
+	FailOnUnknown bool
+	// WatchDiff only prints the jobs whose result changed since the previous query
+	// when the status is repeated or watched
+	WatchDiff bool
+}
+
+// JobStatus is the status of a single job.
+// It is serialized with its fields in the order they are declared here.
+type JobStatus struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+	// FailedTests is only set with the Annotate option
+	FailedTests []string `json:"failedTests,omitempty"`
+	// Progress is the estimated progress of a running build in percent
+	Progress int `json:"progress,omitempty"`
+	// Elapsed is the time a running build has been running for, e.g. "12m3s"
+	Elapsed string `json:"elapsed,omitempty"`
+	// Causes tell why the last build was started, e.g. "timer" or "user:jane"
+	Causes []string `json:"causes,omitempty"`
+	// Duration of the last build in milliseconds like in the Jenkins API, so far for running builds
+	Duration int64 `json:"duration,omitempty"`
+}
+
+// statusField is a field of JobStatus which can be selected with --fields
+type statusField struct {
+	name  string
+	value func(JobStatus) interface{}
+}
+
+var statusFields = []statusField{
+	{"name", func(s JobStatus) interface{} { return s.Name }},
+	{"url", func(s JobStatus) interface{} { return s.URL }},
+	{"result", func(s JobStatus) interface{} { return s.Result }},
+	{"error", func(s JobStatus) interface{} { return s.Error }},
+	{"failedTests", func(s JobStatus) interface{} { return s.FailedTests }},
+	{"progress", func(s JobStatus) interface{} { return s.Progress }},
+	{"elapsed", func(s JobStatus) interface{} { return s.Elapsed }},
+	{"causes", func(s JobStatus) interface{} { return s.Causes }},
+	{"duration", func(s JobStatus) interface{} { return s.Duration }},
+}
+
+// stateChanges counts how often the result of a job changed over repeated runs
+type stateChanges struct {
+	Name    string   `json:"name"`
+	Changes int      `json:"changes"`
+	Results []string `json:"results"`
+}
+
+func NewStatus(jenkins *gojenkins.Jenkins, regex string, options StatusOptions) *Status {
+	return &Status{jenkins, regex, options}
 }
 
 func (s Status) Exec() error {
-	jobs, err := job.FindMatchingJobs(s.jenkins, s.regex)
+	// Running jobs count as fine, so --quiet would drop all of them
+	if s.options.Quiet && s.options.OnlyBuilding {
+		return fmt.Errorf("--quiet and --only-building can't be combined, --quiet hides running jobs")
+	}
+	fields, err := selectStatusFields(s.options.Fields)
+	if err != nil {
+		return err
+	}
+	assertions, err := parseAssertions(s.options.Assert)
+	if err != nil {
+		return err
+	}
+
+	var statuses, previous []JobStatus
+	var changes []*stateChanges
+	byName := map[string]*stateChanges{}
+	watching := s.options.WatchUntil != ""
+	run := 0
+	// query runs the status query once and reports whether it is the last time.
+	// Failed queries are transient, failures to print or notify are not.
+	query := func() (bool, error) {
+		statuses, err = s.Collect()
+		if err != nil {
+			return false, err
+		}
+		if s.options.WatchDiff && run > 0 {
+			err = s.printResultChanges(previous, statuses, fields)
+		} else {
+			err = s.print(statuses, fields)
+		}
+		if err != nil {
+			return true, err
+		}
+		// Repeated queries only notify, archive and record again if something changed
+		if run == 0 || !sameJobs(failingJobs(previous), failingJobs(statuses)) {
+			if err = s.notify(statuses); err != nil {
+				return true, err
+			}
+		}
+		if run == 0 || resultsChanged(previous, statuses) {
+			if s.options.ArchiveLogs != "" {
+				archiveFailedLogs(s.jenkins, s.options.ArchiveLogs, statuses)
+			}
+			if err = recordChanges(statuses); err != nil {
+				warnLocalState("Cannot record result changes: %v", err)
+			}
+		}
+		previous = statuses
+		run++
+
+		for _, status := range statuses {
+			c, ok := byName[status.Name]
+			if !ok {
+				c = &stateChanges{Name: status.Name}
+				byName[status.Name] = c
+				changes = append(changes, c)
+			}
+			if len(c.Results) > 0 && c.Results[len(c.Results)-1] != status.Result {
+				c.Changes++
+			}
+			c.Results = append(c.Results, status.Result)
+		}
+
+		if watching {
+			if watching, err = watchPending(s.options.WatchUntil, statuses); err != nil {
+				return true, err
+			}
+		}
+		return !watching && run >= s.options.Repeat, nil
+	}
+	// Repeated queries wait --poll-interval in between and share the retry budget with all other polls
+	if s.options.Repeat > 1 || watching {
+		err = poll(query)
+	} else {
+		_, err = query()
+	}
 	if err != nil {
 		return err
 	}
 
-	var wg sync.WaitGroup
-	for _, job := range jobs {
-		wg.Add(1)
-		go func(job gojenkins.InnerJob) {
-			defer wg.Done()
-			s.print(job)
-		}(job)
+	if s.options.Repeat > 1 {
+		if err = s.printChanges(changes); err != nil {
+			return err
+		}
+	}
+	// Only the last run counts
+	if s.options.FailOnUnknown {
+		if err = checkUnknown(statuses); err != nil {
+			return err
+		}
+	}
+	return checkAssertions(assertions, countResults(statuses))
+}
+
+// warnLocalState reports a failure to write local state like the change log or the result cache.
+// Those are a convenience, so they don't fail the status.
+func warnLocalState(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// checkUnknown returns an UnknownResultError listing the jobs whose result couldn't be determined
+func checkUnknown(statuses []JobStatus) error {
+	var unknown []string
+	for _, status := range statuses {
+		if status.Result == "UNKNOWN" {
+			unknown = append(unknown, status.Name)
+		}
+	}
+	if len(unknown) > 0 {
+		return &UnknownResultError{fmt.Errorf("%v jobs: %v", len(unknown), strings.Join(unknown, ", "))}
 	}
-	wg.Wait()
 	return nil
 }
 
-func (s Status) print(job gojenkins.InnerJob) error {
+// watchPending tells whether to keep watching the jobs for a condition.
+// It fails if all builds are finished without meeting the condition.
+func watchPending(until string, statuses []JobStatus) (bool, error) {
+	counts := countResults(statuses)
+	switch {
+	case until == "success" && counts["success"] == counts["total"]:
+		return false, nil
+	case until == "failure" && counts["failure"] > 0:
+		return false, nil
+	case counts["running"] > 0:
+		return true, nil
+	case until == "complete":
+		return false, nil
+	}
+	return false, fmt.Errorf("all builds are finished but the condition %q is not met", until)
+}
+
+// Collect resolves the status of all matching jobs without printing anything,
+// so it can be used by other Go programs as well
+func (s Status) Collect() ([]JobStatus, error) {
+	jobs, err := job.FindMatchingJobs(s.jenkins, s.regex)
+	if err != nil {
+		return nil, err
+	}
+	if !s.options.IncludeDisabled {
+		jobs = withoutDisabled(jobs)
+	}
+
 	// Buffer full output to avoid race conditions between jobs
-	yellow := color.New(color.FgYellow).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-	green := color.New(color.FgGreen).SprintFunc()
+	statuses := make([]JobStatus, len(jobs))
+	if s.options.CacheTTL <= 0 {
+		forEach(len(jobs), func(i int) {
+			statuses[i] = s.Resolve(jobs[i])
+		})
+		return statuses, nil
+	}
+
+	cache := loadResultCache()
+	cached := make([]bool, len(jobs))
+	forEach(len(jobs), func(i int) {
+		result, ok := cache[s.resultCacheKey(jobs[i])]
+		if ok && !s.options.Refresh && time.Since(result.Time) < s.options.CacheTTL && result.Status.Result != "RUNNING" {
+			statuses[i], cached[i] = result.Status, true
+			return
+		}
+		statuses[i] = s.Resolve(jobs[i])
+	})
+
+	now := time.Now()
+	for i, status := range statuses {
+		if cached[i] {
+			continue
+		}
+		// Running builds change all the time, their progress and elapsed time would be frozen
+		if status.Result == "RUNNING" {
+			delete(cache, s.resultCacheKey(jobs[i]))
+		} else {
+			cache[s.resultCacheKey(jobs[i])] = cachedResult{status, now}
+		}
+	}
+	if err = saveResultCache(cache); err != nil {
+		warnLocalState("Cannot cache job statuses: %v", err)
+	}
+	return statuses, nil
+}
+
+// resultCacheKey is the key of a job in the result cache. The job URL is extended
+// by the options which change the status, so e.g. statuses without annotations aren't reused for --annotate.
+func (s Status) resultCacheKey(j gojenkins.InnerJob) string {
+	if s.options.Annotate > 0 {
+		return fmt.Sprintf("%v#annotate=%v", j.Url, s.options.Annotate)
+	}
+	return j.Url
+}
+
+func (s Status) print(statuses []JobStatus, fields []statusField) error {
+	return s.render(s.filter(statuses), fields)
+}
+
+// displayNames returns the names of the jobs as shown in the text outputs
+func (s Status) displayNames(statuses []JobStatus) []string {
+	names := displayNames(statuses, s.options.StripPrefix)
+	if s.options.MaxNameWidth > 0 {
+		for i, name := range names {
+			names[i] = truncateMiddle(name, s.options.MaxNameWidth)
+		}
+	}
+	return names
+}
+
+// filter applies the options selecting and ordering the printed jobs
+func (s Status) filter(statuses []JobStatus) []JobStatus {
+	if s.options.Quiet {
+		statuses = withProblems(statuses)
+	}
+	statuses = sortStatuses(statuses, s.options.Sort)
+	if s.options.OnlyBuilding {
+		statuses = building(statuses)
+	}
+	if s.options.Cause != "" || s.options.CauseUser != "" {
+		statuses = s.withCause(statuses)
+	}
+	return statuses
+}
+
+// render prints the statuses in the output format and with the fields of the options
+func (s Status) render(statuses []JobStatus, fields []statusField) error {
+	if len(fields) > 0 {
+		return printStatusFields(statuses, fields, s.options.Output, s.options.Pretty)
+	}
+	if s.options.Output == "json" {
+		return printJSON(statuses, s.options.Pretty)
+	}
+	names := s.displayNames(statuses)
+	if s.options.Output == "compact" {
+		printCompact(statuses, names)
+		return nil
+	}
+	if s.options.Output == "summary" {
+		printSummary(statuses, names)
+		return nil
+	}
+	for i, status := range statuses {
+		switch {
+		case status.Progress > 0:
+			fmt.Printf("%v %v (%v) %v for %v\n", resultMarker(status.Result), names[i], status.URL, progressText(status.Progress), status.Elapsed)
+		case status.Elapsed != "":
+			fmt.Printf("%v %v (%v) running for %v\n", resultMarker(status.Result), names[i], status.URL, status.Elapsed)
+		default:
+			fmt.Printf("%v %v (%v)\n", resultMarker(status.Result), names[i], status.URL)
+		}
+		for _, test := range status.FailedTests {
+			fmt.Printf("    %v %v\n", Bad, test)
+		}
+	}
+	return nil
+}
 
-	build, err := s.jenkins.GetJob(job.Name)
+// notify sends the failing jobs to the webhook, if one is configured
+func (s Status) notify(statuses []JobStatus) error {
+	if s.options.NotifyWebhook == "" {
+		return nil
+	}
+	failing := failingJobs(statuses)
+	if len(failing) == 0 {
+		return nil
+	}
+	if err := notifyWebhook(s.jenkins.Requester.Client, s.options.NotifyWebhook, failing); err != nil {
+		return fmt.Errorf("cannot notify webhook: %v", err)
+	}
+	return nil
+}
+
+// failingJobs returns the jobs whose last build failed
+func failingJobs(statuses []JobStatus) []JobStatus {
+	var failing []JobStatus
+	for _, status := range statuses {
+		if status.Result == "FAILURE" {
+			failing = append(failing, status)
+		}
+	}
+	return failing
+}
+
+// sameJobs tells whether two lists contain the same jobs, no matter in which order
+func sameJobs(a, b []JobStatus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	names := map[string]bool{}
+	for _, status := range a {
+		names[status.Name] = true
+	}
+	for _, status := range b {
+		if !names[status.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// resultsChanged tells whether any job changed its result between two queries, or was added or removed
+func resultsChanged(previous, current []JobStatus) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	results := map[string]string{}
+	for _, status := range previous {
+		results[status.Name] = status.Result
+	}
+	for _, status := range current {
+		if result, ok := results[status.Name]; !ok || result != status.Result {
+			return true
+		}
+	}
+	return false
+}
+
+// compactWidth is the number of markers per row of the compact output
+const compactWidth = 40
+
+// printCompact prints the markers of all jobs in a grid,
+// followed by a legend of the job names by position
+func printCompact(statuses []JobStatus, names []string) {
+	for row := 0; row < len(statuses); row += compactWidth {
+		var markers []string
+		for i := row; i < row+compactWidth && i < len(statuses); i++ {
+			markers = append(markers, resultMarker(statuses[i].Result))
+		}
+		fmt.Printf("%4d %v\n", row+1, strings.Join(markers, ""))
+	}
+	fmt.Println()
+	for i, status := range statuses {
+		fmt.Printf("%4d %v %v\n", i+1, resultMarker(status.Result), names[i])
+	}
+}
+
+// printSummary prints the number of jobs by result, followed by all jobs which didn't succeed
+func printSummary(statuses []JobStatus, names []string) {
+	counts := countResults(statuses)
+	var tally []string
+	for _, name := range assertionCounts[1:] {
+		if counts[name] > 0 {
+			tally = append(tally, fmt.Sprintf("%v %v", counts[name], name))
+		}
+	}
+	fmt.Printf("%v jobs: %v\n", counts["total"], strings.Join(tally, ", "))
+	for i, status := range statuses {
+		if status.Result != "SUCCESS" {
+			fmt.Printf("%v %v (%v)\n", resultMarker(status.Result), names[i], status.URL)
+		}
+	}
+}
+
+// displayNames returns the job names without the given prefix, or without their
+// longest common prefix up to a separator for "auto". Names are never stripped to nothing.
+func displayNames(statuses []JobStatus, prefix string) []string {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = status.Name
+	}
+	if prefix == "auto" {
+		prefix = commonPrefix(names)
+	}
+	for i, name := range names {
+		if stripped := strings.TrimPrefix(name, prefix); stripped != "" {
+			names[i] = stripped
+		}
+	}
+	return names
+}
+
+// truncateMiddle shortens a name to at most width characters by replacing its middle with an ellipsis.
+// The end is kept as it usually tells similar jobs in the same folder apart.
+func truncateMiddle(name string, width int) string {
+	runes := []rune(name)
+	if len(runes) <= width {
+		return name
+	}
+	if width <= 1 {
+		return "…"
+	}
+	head := (width - 1) / 2
+	tail := width - 1 - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
+
+// commonPrefix returns the longest common prefix of all names which ends with a separator,
+// so names like "deploy-api" and "deploy-app" don't end up as "i" and "p"
+func commonPrefix(names []string) string {
+	if len(names) < 2 {
+		return ""
+	}
+	prefix := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix[:strings.LastIndexAny(prefix, "-_./")+1]
+}
+
+// printChanges prints the jobs by how often their state changed, most changes first
+func (s Status) printChanges(changes []*stateChanges) error {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Changes > changes[j].Changes
+	})
+	if s.options.Output == "json" {
+		return printJSON(changes, s.options.Pretty)
+	}
+
+	fmt.Printf("\nState changes over %v runs:\n", s.options.Repeat)
+	for _, c := range changes {
+		fmt.Printf("%v: %v changes (%v)\n", c.Name, c.Changes, strings.Join(c.Results, " → "))
+	}
+	return nil
+}
+
+// selectStatusFields looks up the fields with the given names, keeping their order
+func selectStatusFields(names []string) ([]statusField, error) {
+	var selected []statusField
+	for _, name := range names {
+		found := false
+		for _, field := range statusFields {
+			if field.name == name {
+				selected = append(selected, field)
+				found = true
+				break
+			}
+		}
+		if !found {
+			var valid []string
+			for _, field := range statusFields {
+				valid = append(valid, field.name)
+			}
+			return nil, fmt.Errorf("unknown field %q, valid fields are: %v", name, strings.Join(valid, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// printStatusFields prints only the selected fields of each status,
+// as JSON objects or as tab separated columns
+func printStatusFields(statuses []JobStatus, fields []statusField, output string, pretty bool) error {
+	if output == "json" {
+		objects := make([]orderedObject, len(statuses))
+		for i, status := range statuses {
+			for _, field := range fields {
+				objects[i].set(field.name, field.value(status))
+			}
+		}
+		return printJSON(objects, pretty)
+	}
+
+	for _, status := range statuses {
+		var columns []string
+		for _, field := range fields {
+			columns = append(columns, fmt.Sprint(field.value(status)))
+		}
+		printEntry(strings.Join(columns, "\t"))
+	}
+	return nil
+}
+
+// Resolve returns the status of the last build of a single job
+func (s Status) Resolve(j gojenkins.InnerJob) JobStatus {
+	status := JobStatus{Name: j.Name, URL: j.Url, Result: "UNKNOWN"}
+	// The last result of a disabled job is stale
+	if job.IsDisabled(j) {
+		status.Result = "DISABLED"
+		return status
+	}
+
+	build, err := s.jenkins.GetJob(j.Name)
 	if err != nil {
-		return err
+		var permissionErr *PermissionError
+		if errors.As(Classify(err), &permissionErr) {
+			status.Result = "FORBIDDEN"
+		}
+		status.Error = err.Error()
+		return status
 	}
 
 	lastBuild, err := build.GetLastBuild()
-	var result string
 	if err != nil {
-		result = fmt.Sprintf("UNKNOWN (%v)", err)
+		status.Error = err.Error()
+		return status
+	}
+	if lastBuild.IsRunning() {
+		status.Result = "RUNNING"
+		elapsed := time.Since(lastBuild.GetTimestamp())
+		status.Elapsed = elapsed.Round(time.Second).String()
+		status.Duration = int64(elapsed / time.Millisecond)
+		if progress, ok := estimatedProgress(lastBuild); ok {
+			status.Progress = progress
+		}
 	} else {
-		if lastBuild.IsRunning() {
-			result = "RUNNING"
-		} else {
-			result = lastBuild.GetResult()
+		status.Result = lastBuild.GetResult()
+		status.Duration = lastBuild.GetDuration()
+	}
+	status.Causes = buildCauses(lastBuild)
+	if s.options.Annotate > 0 && (status.Result == "FAILURE" || status.Result == "UNSTABLE") {
+		status.FailedTests = failedTests(lastBuild, s.options.Annotate)
+	}
+	return status
+}
+
+// failedTests returns the names of up to limit failed tests of a build.
+// Builds without a test report have no failed tests.
+func failedTests(build *gojenkins.Build, limit int) []string {
+	report, err := build.GetResultSet()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, suite := range report.Suites {
+		for _, c := range suite.Cases {
+			if c.Status != "FAILED" && c.Status != "REGRESSION" {
+				continue
+			}
+			if len(names) == limit {
+				remaining := int(report.FailCount) - limit
+				if remaining > 0 {
+					names = append(names, fmt.Sprintf("... and %v more", remaining))
+				}
+				return names
+			}
+			names = append(names, c.ClassName+"."+c.Name)
 		}
 	}
+	return names
+}
 
-	marker := yellow("?")
-	switch result {
-	case "RUNNING":
-		marker = green("↻")
-	case "SUCCESS":
-		marker = green("✓")
-	case "FAILURE":
-		marker = red("✗")
+func withoutDisabled(jobs []gojenkins.InnerJob) []gojenkins.InnerJob {
+	var enabled []gojenkins.InnerJob
+	for _, j := range jobs {
+		if !job.IsDisabled(j) {
+			enabled = append(enabled, j)
+		}
 	}
+	return enabled
+}
 
-	fmt.Printf("%v %v (%v)\n", marker, job.Name, job.Url)
+// withProblems drops the jobs which are fine, i.e. successful, running or disabled ones
+func withProblems(statuses []JobStatus) []JobStatus {
+	var problems []JobStatus
+	for _, status := range statuses {
+		switch status.Result {
+		case "SUCCESS", "RUNNING", "DISABLED":
+		default:
+			problems = append(problems, status)
+		}
+	}
+	return problems
+}
+
+// building returns the jobs with a running build
+func building(statuses []JobStatus) []JobStatus {
+	var running []JobStatus
+	for _, status := range statuses {
+		if status.Result == "RUNNING" {
+			running = append(running, status)
+		}
+	}
+	return running
+}
+
+// withCause returns the jobs whose last build was started in the way selected by the options
+func (s Status) withCause(statuses []JobStatus) []JobStatus {
+	var matching []JobStatus
+	for _, status := range statuses {
+		if causedBy(status.Causes, s.options.Cause, s.options.CauseUser) {
+			matching = append(matching, status)
+		}
+	}
+	return matching
+}
+
+// sortStatuses returns the statuses ordered by "name" or by "duration", longest first.
+// Any other order keeps the order of Jenkins.
+func sortStatuses(statuses []JobStatus, order string) []JobStatus {
+	sorted := append([]JobStatus(nil), statuses...)
+	switch order {
+	case "name":
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	case "duration":
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Duration > sorted[j].Duration })
+	}
+	return sorted
+}
+
+// printResultChanges prints the jobs whose result changed between two queries, and new jobs.
+// They go through the same filters as print, only the text output shows the transitions.
+func (s Status) printResultChanges(previous, current []JobStatus, fields []statusField) error {
+	results := map[string]string{}
+	for _, status := range previous {
+		results[status.Name] = status.Result
+	}
+	var changed []JobStatus
+	for _, status := range current {
+		if from, ok := results[status.Name]; !ok || from != status.Result {
+			changed = append(changed, status)
+		}
+	}
+	changed = s.filter(changed)
+	if len(fields) > 0 || (s.options.Output != "" && s.options.Output != "text") {
+		return s.render(changed, fields)
+	}
+
+	names := s.displayNames(changed)
+	for i, status := range changed {
+		if from, ok := results[status.Name]; ok {
+			fmt.Printf("%v %v: %v → %v\n", resultMarker(status.Result), names[i], from, status.Result)
+		} else {
+			fmt.Printf("%v %v: new, %v\n", resultMarker(status.Result), names[i], status.Result)
+		}
+	}
 	return nil
 }
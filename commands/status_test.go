@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bndr/gojenkins"
+)
+
+func TestPrintResultChanges(t *testing.T) {
+	previous := []JobStatus{
+		{Name: "prod-api", URL: "u1", Result: "SUCCESS"},
+		{Name: "prod-web", URL: "u2", Result: "RUNNING"},
+		{Name: "prod-db", URL: "u3", Result: "SUCCESS"},
+	}
+	current := []JobStatus{
+		{Name: "prod-api", URL: "u1", Result: "SUCCESS"},
+		{Name: "prod-web", URL: "u2", Result: "FAILURE"},
+		{Name: "prod-db", URL: "u3", Result: "RUNNING"},
+		{Name: "prod-new", URL: "u4", Result: "UNSTABLE"},
+	}
+	tests := []struct {
+		name    string
+		options StatusOptions
+		fields  []string
+		want    string
+	}{
+		{
+			name:    "text",
+			options: StatusOptions{Output: "text"},
+			want: resultMarker("FAILURE") + " prod-web: RUNNING → FAILURE\n" +
+				resultMarker("RUNNING") + " prod-db: SUCCESS → RUNNING\n" +
+				resultMarker("UNSTABLE") + " prod-new: new, UNSTABLE\n",
+		},
+		{
+			name:    "quiet and strip prefix",
+			options: StatusOptions{Output: "text", Quiet: true, StripPrefix: "prod-"},
+			want: resultMarker("FAILURE") + " web: RUNNING → FAILURE\n" +
+				resultMarker("UNSTABLE") + " new: new, UNSTABLE\n",
+		},
+		{
+			name:    "fields",
+			options: StatusOptions{Output: "text", Quiet: true},
+			fields:  []string{"name"},
+			want:    "prod-web\nprod-new\n",
+		},
+		{
+			name:    "json",
+			options: StatusOptions{Output: "json", OnlyBuilding: true},
+			want:    `[{"name":"prod-db","url":"u3","result":"RUNNING"}]` + "\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields, err := selectStatusFields(test.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := Status{options: test.options}
+			got, err := captureStdout(t, func() error { return s.printResultChanges(previous, current, fields) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStatusQuietOnlyBuilding(t *testing.T) {
+	s := NewStatus(nil, ".*", StatusOptions{Quiet: true, OnlyBuilding: true})
+	if err := s.Exec(); err == nil {
+		t.Error("expected an error for --quiet with --only-building")
+	}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	j := gojenkins.InnerJob{Name: "web", Url: "https://jenkins.example.com/job/web/"}
+	plain := Status{}.resultCacheKey(j)
+	annotated := Status{options: StatusOptions{Annotate: 3}}.resultCacheKey(j)
+	if plain == annotated {
+		t.Errorf("statuses with and without annotations share the key %q", plain)
+	}
+	if other := (Status{options: StatusOptions{Annotate: 5}}).resultCacheKey(j); other == annotated {
+		t.Errorf("statuses with different annotations share the key %q", other)
+	}
+}
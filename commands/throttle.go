@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	triggerMu sync.Mutex
+	// triggerInterval is the minimum time between two triggered builds, 0 for no limit
+	triggerInterval time.Duration
+	// nextTrigger is the earliest time the next build may be triggered
+	nextTrigger time.Time
+)
+
+// SetTriggerRate limits how many builds per second are triggered at most, 0 removes the limit.
+// Triggering hundreds of builds at once floods the queue and can trip the master.
+func SetTriggerRate(perSecond float64) {
+	triggerInterval = 0
+	if perSecond > 0 {
+		triggerInterval = time.Duration(float64(time.Second) / perSecond)
+	}
+}
+
+// throttleTrigger blocks until the next build may be triggered.
+// It is safe to call from multiple goroutines, each call reserves its own slot.
+func throttleTrigger() {
+	if triggerInterval <= 0 {
+		return
+	}
+	triggerMu.Lock()
+	now := time.Now()
+	if nextTrigger.Before(now) {
+		nextTrigger = now
+	}
+	wait := nextTrigger.Sub(now)
+	nextTrigger = nextTrigger.Add(triggerInterval)
+	triggerMu.Unlock()
+	time.Sleep(wait)
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/job"
+)
+
+// runningBuild is a build which is currently running
+type runningBuild struct {
+	job      string
+	number   int64
+	elapsed  time.Duration
+	progress int
+	// estimated is false if Jenkins has no estimate for the build
+	estimated bool
+}
+
+type Top struct {
+	jenkins *gojenkins.Jenkins
+	regex   string
+}
+
+func NewTop(jenkins *gojenkins.Jenkins, regex string) *Top {
+	return &Top{jenkins, regex}
+}
+
+func (t Top) Exec() error {
+	jobs, err := job.FindMatchingJobs(t.jenkins, t.regex)
+	if err != nil {
+		return err
+	}
+	// Jenkins animates the color of jobs with a running build,
+	// which saves fetching the last build of all the other jobs
+	var building []gojenkins.InnerJob
+	for _, j := range jobs {
+		if strings.HasSuffix(j.Color, "_anime") {
+			building = append(building, j)
+		}
+	}
+
+	builds := make([]*runningBuild, len(building))
+	forEach(len(building), func(i int) {
+		jenkinsJob, err := t.jenkins.GetJob(building[i].Name)
+		if err != nil {
+			return
+		}
+		build, err := jenkinsJob.GetLastBuild()
+		if err != nil || !build.IsRunning() {
+			return
+		}
+		progress, ok := estimatedProgress(build)
+		builds[i] = &runningBuild{building[i].Name, build.GetBuildNumber(), time.Since(build.GetTimestamp()), progress, ok}
+	})
+
+	var running []*runningBuild
+	for _, build := range builds {
+		if build != nil {
+			running = append(running, build)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].elapsed > running[j].elapsed
+	})
+
+	for _, build := range running {
+		line := fmt.Sprintf("%v %v [%v] running for %v", Running, build.job, build.number, build.elapsed.Round(time.Second))
+		if build.estimated {
+			line += ", " + progressText(build.progress)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
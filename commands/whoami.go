@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+)
+
+type WhoAmI struct {
+	jenkins *gojenkins.Jenkins
+}
+
+// whoAmIResponse is the payload of Jenkins' /whoAmI/api/json endpoint
+type whoAmIResponse struct {
+	Name          string   `json:"name"`
+	Anonymous     bool     `json:"anonymous"`
+	Authenticated bool     `json:"authenticated"`
+	Authorities   []string `json:"authorities"`
+}
+
+func NewWhoAmI(jenkins *gojenkins.Jenkins) *WhoAmI {
+	return &WhoAmI{jenkins}
+}
+
+func (w WhoAmI) Exec() error {
+	var me whoAmIResponse
+	resp, err := w.jenkins.Requester.GetJSON("/whoAmI", &me, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Jenkins answered with HTTP %v on %v", resp.StatusCode, w.jenkins.Server)
+	}
+	if me.Anonymous || !me.Authenticated {
+		fmt.Printf("%v Not authenticated on %v (anonymous)\n", Bad, w.jenkins.Server)
+		return fmt.Errorf("credentials were not accepted, check JENKINS_USER and JENKINS_PW")
+	}
+
+	fmt.Printf("%v Authenticated as %v on %v (Jenkins %v)\n", Good, me.Name, w.jenkins.Server, w.jenkins.Version)
+	if len(me.Authorities) > 0 {
+		fmt.Printf("Authorities: %v\n", strings.Join(me.Authorities, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+	"github.com/mre/riffraff/commands"
+	"github.com/mre/riffraff/job"
+)
+
+// outputFormats are all output formats, each command supports some of them
+var outputFormats = map[string]bool{"text": true, "json": true, "compact": true, "summary": true, "ndjson": true}
+
+// colorThemes are the themes of the markers
+var colorThemes = map[string]bool{"dark": true, "light": true, "mono": true}
+
+// profile is a named Jenkins instance from the config file.
+// There is no password setting on purpose, use a credential command instead.
+type profile struct {
+	URL               string `json:"url"`
+	User              string `json:"user"`
+	CredentialCommand string `json:"credentialCommand"`
+	// JobPrefix is prepended to the job names of this instance, e.g. "prod-"
+	JobPrefix string `json:"jobPrefix"`
+}
+
+// config is the content of ~/.riffraff/config.json
+type config struct {
+	// Output is the default output format of all commands supporting it, e.g. "json"
+	Output string `json:"output"`
+	// Theme is the default color theme of the markers, "dark", "light" or "mono"
+	Theme    string             `json:"theme"`
+	Profiles map[string]profile `json:"profiles"`
+	// Aliases are short names for jobs, e.g. "web" for "team-platform-webapp-build"
+	Aliases map[string]string `json:"aliases"`
+}
+
+// configFile returns the path of the config file
+func configFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".riffraff", "config.json"), nil
+}
+
+// loadConfig reads the config file, a missing file is an empty config
+func loadConfig() (config, error) {
+	var cfg config
+	path, err := configFile()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %v: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// selectProfile returns the profile selected with --profile or its shorthand --env
+func selectProfile(cfg config, profileName, envName string) (profile, error) {
+	if profileName != "" && envName != "" && profileName != envName {
+		return profile{}, fmt.Errorf("--profile %v and --env %v select different profiles", profileName, envName)
+	}
+	name := profileName
+	if name == "" {
+		name = envName
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("unknown profile %q, please add it to the config file", name)
+	}
+	return p, nil
+}
+
+// outputFormat returns the output format from the command line, or the default output
+// of the config file if the command supports it, or text
+func (c config) outputFormat(flag string, formats ...string) string {
+	if flag != "" {
+		return flag
+	}
+	for _, format := range formats {
+		if format == c.Output {
+			return format
+		}
+	}
+	return "text"
+}
+
+// colorTheme returns the theme from the command line or RIFFRAFF_THEME,
+// or the theme of the config file, or dark
+func (c config) colorTheme(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if c.Theme != "" {
+		return c.Theme
+	}
+	return "dark"
+}
+
+// jobName returns the name of the job an alias stands for, or the argument unchanged
+func (c config) jobName(arg string) string {
+	if name, ok := c.Aliases[arg]; ok {
+		return name
+	}
+	return arg
+}
+
+// jobPattern returns a pattern matching exactly the job an alias stands for,
+// or the argument unchanged if it isn't an alias
+func (c config) jobPattern(arg string) string {
+	name, ok := c.Aliases[arg]
+	if !ok {
+		return arg
+	}
+	// Patterns are matched against the names without the prefix of the profile
+	name = strings.TrimPrefix(name, job.Settings.JobPrefix)
+	if job.Settings.Glob {
+		return globQuote(name)
+	}
+	return "^" + regexp.QuoteMeta(name) + "$"
+}
+
+// globQuote escapes the wildcards in a job name, globs are anchored already.
+// Globs have no escape character, so each wildcard becomes a class of its own.
+func globQuote(name string) string {
+	var glob strings.Builder
+	for _, c := range name {
+		if c == '*' || c == '?' || c == '[' {
+			glob.WriteString("[" + string(c) + "]")
+		} else {
+			glob.WriteRune(c)
+		}
+	}
+	return glob.String()
+}
+
+// validateConfig checks the config file for unknown settings and each profile for missing ones.
+// With ping, it also connects to the instance of each profile to verify the credentials.
+func validateConfig(cfg config, ping bool, options clientOptions) error {
+	path, err := configFile()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("there is no config file at %v", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	var problems int
+	report := func(format string, a ...interface{}) {
+		problems++
+		fmt.Printf("%v %v\n", commands.Bad, fmt.Sprintf(format, a...))
+	}
+	// Typos like "credential_command" would otherwise be ignored silently
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&config{}); err != nil {
+		report("%v", err)
+	}
+	if cfg.Output != "" && !outputFormats[cfg.Output] {
+		formats := make([]string, 0, len(outputFormats))
+		for format := range outputFormats {
+			formats = append(formats, format)
+		}
+		sort.Strings(formats)
+		report("unknown output %q, expected one of %v", cfg.Output, strings.Join(formats, ", "))
+	}
+	if cfg.Theme != "" && !colorThemes[cfg.Theme] {
+		report("unknown theme %q, expected dark, light or mono", cfg.Theme)
+	}
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		if u, err := url.Parse(p.URL); p.URL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			report("profile %v: url %q must be an http or https URL", name, p.URL)
+			continue
+		}
+		if p.User == "" {
+			report("profile %v: user is missing", name)
+			continue
+		}
+		if !ping {
+			fmt.Printf("%v profile %v: %v as %v\n", commands.Good, name, p.URL, p.User)
+			continue
+		}
+		if err := pingProfile(p, options); err != nil {
+			report("profile %v: %v", name, err)
+			continue
+		}
+		fmt.Printf("%v profile %v: authenticated as %v on %v\n", commands.Good, name, p.User, p.URL)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("found %v problems in %v", problems, path)
+	}
+	return nil
+}
+
+// authStatus returns the status code of a minimal request to the root of Jenkins.
+// Only the mode is requested, the full root page lists every job, which is slow on big instances.
+func authStatus(jenkins *gojenkins.Jenkins) (int, error) {
+	var root struct {
+		Mode string `json:"mode"`
+	}
+	resp, err := jenkins.Requester.GetJSON("/", &root, map[string]string{"tree": "mode"})
+	if err != nil {
+		return 0, err
+	}
+	return resp.StatusCode, nil
+}
+
+// pingProfile connects to the instance of a profile and checks that its credentials are accepted
+func pingProfile(p profile, options clientOptions) error {
+	password := os.Getenv("JENKINS_PW")
+	if p.CredentialCommand != "" {
+		secret, err := runCredentialCommand(p.CredentialCommand)
+		if err != nil {
+			return err
+		}
+		password = secret
+	}
+	client, err := newHTTPClient(p.URL, options)
+	if err != nil {
+		return err
+	}
+	jenkins := gojenkins.CreateJenkins(client, p.URL, p.User, password)
+	status, err := authStatus(jenkins)
+	if err != nil {
+		return &commands.ConnectionError{Err: err}
+	}
+	if status == 401 || status == 403 {
+		return &commands.AuthError{Err: fmt.Errorf("credentials were not accepted (HTTP %v)", status)}
+	}
+	return nil
+}
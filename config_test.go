@@ -0,0 +1,61 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/mre/riffraff/job"
+)
+
+func TestJobPattern(t *testing.T) {
+	cfg := config{Aliases: map[string]string{"web": "prod-web", "odd": "prod-a*b?[c]"}}
+	tests := []struct {
+		name    string
+		glob    bool
+		arg     string
+		matches []string
+		misses  []string
+	}{
+		{name: "not an alias", arg: "^api", matches: []string{"api-1"}},
+		{name: "regex", arg: "web", matches: []string{"web"}, misses: []string{"web-old", "old-web"}},
+		{name: "regex with special characters", arg: "odd", matches: []string{"a*b?[c]"}, misses: []string{"ab", "aaxbc"}},
+		{name: "glob", glob: true, arg: "web", matches: []string{"web"}, misses: []string{"web-old"}},
+		{name: "glob with wildcards", glob: true, arg: "odd", matches: []string{"a*b?[c]"}, misses: []string{"axbxc", "a*b?c"}},
+	}
+
+	defer func(prefix string, glob bool) {
+		job.Settings.JobPrefix, job.Settings.Glob = prefix, glob
+	}(job.Settings.JobPrefix, job.Settings.Glob)
+	job.Settings.JobPrefix = "prod-"
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			job.Settings.Glob = test.glob
+			pattern := regexp.MustCompile(job.Pattern(cfg.jobPattern(test.arg)))
+			for _, name := range test.matches {
+				if !pattern.MatchString(name) {
+					t.Errorf("%v doesn't match %q", pattern, name)
+				}
+			}
+			for _, name := range test.misses {
+				if pattern.MatchString(name) {
+					t.Errorf("%v matches %q", pattern, name)
+				}
+			}
+		})
+	}
+}
+
+func TestColorTheme(t *testing.T) {
+	tests := []struct {
+		flag, configured, want string
+	}{
+		{"", "", "dark"},
+		{"", "light", "light"},
+		{"mono", "light", "mono"},
+	}
+	for _, test := range tests {
+		if got := (config{Theme: test.configured}).colorTheme(test.flag); got != test.want {
+			t.Errorf("colorTheme(%q) with %q configured = %q, want %q", test.flag, test.configured, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// cachedResponse is a response which can be revalidated with its ETag or Last-Modified header
+type cachedResponse struct {
+	header http.Header
+	body   []byte
+}
+
+// cachingTransport revalidates GET requests to the API it has seen before with If-None-Match and
+// If-Modified-Since, and answers them from memory if Jenkins replies 304 Not Modified.
+// Polling the same jobs over and over then costs Jenkins a lot less.
+type cachingTransport struct {
+	next      http.RoundTripper
+	mu        sync.Mutex
+	responses map[string]cachedResponse
+}
+
+func newCachingTransport(next http.RoundTripper) *cachingTransport {
+	return &cachingTransport{next: next, responses: map[string]cachedResponse{}}
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Artifacts and console output can be huge, so only API responses are kept
+	if req.Method != "GET" || req.Header.Get("Range") != "" || !strings.Contains(req.URL.Path, "/api/") {
+		return t.next.RoundTrip(req)
+	}
+	key := req.URL.String()
+	t.mu.Lock()
+	cached, ok := t.responses[key]
+	t.mu.Unlock()
+
+	if ok {
+		// RoundTrippers must not modify the request
+		req = req.Clone(req.Context())
+		if etag := cached.header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		resp.StatusCode, resp.Status = http.StatusOK, "200 OK"
+		resp.Header = cached.header.Clone()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+		return resp, nil
+	}
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.responses[key] = cachedResponse{resp.Header.Clone(), body}
+	t.mu.Unlock()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,2 @@
+// Package job finds the Jenkins jobs matching a regular expression.
+package job
@@ -1,24 +1,195 @@
 package job
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/bndr/gojenkins"
 )
 
-// FindMatchingJobs finds all jobs matching the given regex
+// Settings apply to all calls of FindMatchingJobs.
+// They are set once from the command line flags.
+var Settings struct {
+	// ErrorOnEmpty makes it an error if no job matches
+	ErrorOnEmpty bool
+	// Exclude removes jobs matching any of these regular expressions
+	Exclude []string
+	// PageSize is the number of jobs fetched per request, 0 fetches all at once
+	PageSize int
+	// Glob interprets all patterns as shell globs like "deploy-*" instead of regular expressions
+	Glob bool
+	// JobPrefix restricts matching to jobs with this prefix and matches the patterns against
+	// the rest of the name, so the same pattern works on instances with different naming conventions
+	JobPrefix string
+}
+
+// jobPage is the root of the Jenkins API restricted to a range of jobs
+type jobPage struct {
+	Jobs []gojenkins.InnerJob `json:"jobs"`
+}
+
+// FindMatchingJobs finds all jobs matching the given regex.
+// Only top-level jobs are matched, jobs inside of folders are not listed.
 func FindMatchingJobs(jenkins *gojenkins.Jenkins, regex string) ([]gojenkins.InnerJob, error) {
-	jobs, err := jenkins.GetAllJobNames()
+	matcher, err := NewMatcher(regex)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := getAllJobNames(jenkins, Settings.PageSize)
 	if err != nil {
 		return nil, err
 	}
 
 	var matchingJobs []gojenkins.InnerJob
 	for _, job := range jobs {
-		if match, _ := regexp.MatchString(regex, job.Name); match {
+		if matcher.Match(job.Name) {
 			matchingJobs = append(matchingJobs, job)
 		}
 	}
 
+	if Settings.ErrorOnEmpty && len(matchingJobs) == 0 {
+		return nil, fmt.Errorf("no jobs match %q", regex)
+	}
 	return matchingJobs, nil
 }
+
+// Matcher matches job names the same way as FindMatchingJobs, for commands which get the names
+// from somewhere else than the job list, like the queue
+type Matcher struct {
+	pattern  *regexp.Regexp
+	excludes []*regexp.Regexp
+}
+
+// NewMatcher compiles a pattern given on the command line and the excluded patterns of Settings
+func NewMatcher(regex string) (*Matcher, error) {
+	pattern, err := regexp.Compile(Pattern(regex))
+	if err != nil {
+		return nil, err
+	}
+	excludes := make([]*regexp.Regexp, len(Settings.Exclude))
+	for i, exclude := range Settings.Exclude {
+		if excludes[i], err = regexp.Compile(Pattern(exclude)); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern: %v", err)
+		}
+	}
+	return &Matcher{pattern, excludes}, nil
+}
+
+// Match tells if a job has the prefix of the profile and the rest of its name
+// matches the pattern, but none of the excluded ones
+func (m *Matcher) Match(jobName string) bool {
+	if !strings.HasPrefix(jobName, Settings.JobPrefix) {
+		return false
+	}
+	name := strings.TrimPrefix(jobName, Settings.JobPrefix)
+	return m.pattern.MatchString(name) && !matchesAny(m.excludes, name)
+}
+
+// Pattern returns the regular expression for a pattern given on the command line,
+// which is a shell glob if Settings.Glob is set
+func Pattern(pattern string) string {
+	// The default pattern of all commands matches everything in both modes
+	if !Settings.Glob || pattern == ".*" {
+		return pattern
+	}
+
+	var regex strings.Builder
+	regex.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			regex.WriteString(".*")
+		case '?':
+			regex.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				regex.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			regex.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	regex.WriteString("$")
+	return regex.String()
+}
+
+// getAllJobNames lists all jobs in pages of the given size,
+// which is a lot lighter on huge instances than listing them all at once
+func getAllJobNames(jenkins *gojenkins.Jenkins, pageSize int) ([]gojenkins.InnerJob, error) {
+	if pageSize <= 0 {
+		return jenkins.GetAllJobNames()
+	}
+
+	var jobs []gojenkins.InnerJob
+	for start := 0; ; start += pageSize {
+		var page jobPage
+		query := map[string]string{
+			"tree": fmt.Sprintf("jobs[_class,name,url,color]{%d,%d}", start, start+pageSize),
+		}
+		resp, err := jenkins.Requester.GetJSON("/", &page, query)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("cannot list jobs: HTTP %v", resp.StatusCode)
+		}
+		jobs = append(jobs, page.Jobs...)
+		if len(page.Jobs) < pageSize {
+			return jobs, nil
+		}
+	}
+}
+
+// folderClasses are the classes of items which contain other jobs instead of being built themselves
+var folderClasses = []string{
+	"com.cloudbees.hudson.plugins.folder.Folder",
+	"jenkins.branch.OrganizationFolder",
+	"org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject",
+}
+
+// IsFolder reports whether an item is a folder, an organization folder or a multibranch project
+func IsFolder(job gojenkins.InnerJob) bool {
+	for _, class := range folderClasses {
+		if job.Class == class {
+			return true
+		}
+	}
+	return false
+}
+
+// OfType returns only the folders ("folder"), only the other jobs ("job"), or all of them ("all")
+func OfType(jobs []gojenkins.InnerJob, kind string) []gojenkins.InnerJob {
+	if kind == "all" {
+		return jobs
+	}
+	var filtered []gojenkins.InnerJob
+	for _, job := range jobs {
+		if IsFolder(job) == (kind == "folder") {
+			filtered = append(filtered, job)
+		}
+	}
+	return filtered
+}
+
+// IsDisabled reports whether a job is disabled
+func IsDisabled(job gojenkins.InnerJob) bool {
+	return strings.HasPrefix(job.Color, "disabled")
+}
+
+func matchesAny(patterns []*regexp.Regexp, name string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
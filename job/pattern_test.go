@@ -0,0 +1,190 @@
+package job
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		glob    bool
+		pattern string
+		want    string
+		matches []string
+		misses  []string
+	}{
+		{
+			name:    "regex is unchanged",
+			pattern: "deploy-.*",
+			want:    "deploy-.*",
+			matches: []string{"deploy-web", "old-deploy-web"},
+		},
+		{
+			name:    "regex is not anchored",
+			pattern: "web",
+			want:    "web",
+			matches: []string{"deploy-web-prod"},
+		},
+		{
+			name:    "glob characters are regex syntax in regex mode",
+			pattern: "deploy-*",
+			want:    "deploy-*",
+			matches: []string{"deploy", "deploy-web"},
+		},
+		{
+			name:    "default pattern in glob mode",
+			glob:    true,
+			pattern: ".*",
+			want:    ".*",
+			matches: []string{"anything"},
+		},
+		{
+			name:    "glob is anchored",
+			glob:    true,
+			pattern: "web",
+			want:    "^web$",
+			matches: []string{"web"},
+			misses:  []string{"deploy-web", "web-prod"},
+		},
+		{
+			name:    "star",
+			glob:    true,
+			pattern: "deploy-*",
+			want:    "^deploy-.*$",
+			matches: []string{"deploy-", "deploy-web"},
+			misses:  []string{"old-deploy-web"},
+		},
+		{
+			name:    "question mark",
+			glob:    true,
+			pattern: "web-?",
+			want:    "^web-.$",
+			matches: []string{"web-1"},
+			misses:  []string{"web-", "web-12"},
+		},
+		{
+			name:    "class",
+			glob:    true,
+			pattern: "web-[0-9]",
+			want:    "^web-[0-9]$",
+			matches: []string{"web-1"},
+			misses:  []string{"web-a"},
+		},
+		{
+			name:    "negated class",
+			glob:    true,
+			pattern: "web-[!0-9]",
+			want:    "^web-[^0-9]$",
+			matches: []string{"web-a"},
+			misses:  []string{"web-1"},
+		},
+		{
+			name:    "unclosed bracket is literal",
+			glob:    true,
+			pattern: "web-[1",
+			want:    `^web-\[1$`,
+			matches: []string{"web-[1"},
+			misses:  []string{"web-1"},
+		},
+		{
+			name:    "regex characters are literal",
+			glob:    true,
+			pattern: "a.b+(c)",
+			want:    `^a\.b\+\(c\)$`,
+			matches: []string{"a.b+(c)"},
+			misses:  []string{"axbb(c)"},
+		},
+		{
+			name:    "empty glob only matches an empty name",
+			glob:    true,
+			pattern: "",
+			want:    "^$",
+			matches: []string{""},
+			misses:  []string{"web"},
+		},
+	}
+
+	defer func(glob bool) { Settings.Glob = glob }(Settings.Glob)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Settings.Glob = test.glob
+			got := Pattern(test.pattern)
+			if got != test.want {
+				t.Fatalf("Pattern(%q) = %q, want %q", test.pattern, got, test.want)
+			}
+			regex := regexp.MustCompile(got)
+			for _, name := range test.matches {
+				if !regex.MatchString(name) {
+					t.Errorf("%q doesn't match %q", got, name)
+				}
+			}
+			for _, name := range test.misses {
+				if regex.MatchString(name) {
+					t.Errorf("%q matches %q", got, name)
+				}
+			}
+		})
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		exclude []string
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{
+			name:    "without prefix",
+			pattern: "web",
+			matches: []string{"web", "staging-web"},
+		},
+		{
+			name:    "prefix is required and stripped",
+			prefix:  "prod-",
+			pattern: "^web",
+			matches: []string{"prod-web", "prod-web-api"},
+			misses:  []string{"staging-web", "web", "prod-api-web"},
+		},
+		{
+			name:    "excludes apply to the name without the prefix",
+			prefix:  "prod-",
+			exclude: []string{"^web-old$"},
+			pattern: "web",
+			matches: []string{"prod-web"},
+			misses:  []string{"prod-web-old"},
+		},
+	}
+
+	defer func(prefix string, exclude []string) {
+		Settings.JobPrefix, Settings.Exclude = prefix, exclude
+	}(Settings.JobPrefix, Settings.Exclude)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Settings.JobPrefix, Settings.Exclude = test.prefix, test.exclude
+			matcher, err := NewMatcher(test.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range test.matches {
+				if !matcher.Match(name) {
+					t.Errorf("%q doesn't match %q", test.pattern, name)
+				}
+			}
+			for _, name := range test.misses {
+				if matcher.Match(name) {
+					t.Errorf("%q matches %q", test.pattern, name)
+				}
+			}
+		})
+	}
+}
+
+func TestMatcherInvalid(t *testing.T) {
+	if _, err := NewMatcher("web("); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
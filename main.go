@@ -1,88 +1,473 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/bndr/gojenkins"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/mre/riffraff/commands"
+	"github.com/mre/riffraff/job"
 )
 
 var (
-	statusCommand  = kingpin.Command("status", "Show the status of all matching jobs")
-	statusRegexArg = statusCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	statusCommand   = kingpin.Command("status", "Show the status of all matching jobs")
+	statusRegexArg  = statusCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	statusOutput    = statusCommand.Flag("output", "Output format (text, json, compact or summary), defaults to the output of the config file or text").Short('o').Enum("text", "json", "compact", "summary")
+	statusPretty    = statusCommand.Flag("pretty", "Indent the JSON output").Bool()
+	statusDisabled  = statusCommand.Flag("include-disabled", "Include disabled jobs, which are hidden by default").Bool()
+	statusFields    = statusCommand.Flag("fields", "Comma separated list of fields to print, e.g. name,result").String()
+	statusRepeat    = statusCommand.Flag("repeat", "Query the status this many times, every --poll-interval, and report how often each job changed its state").Default("1").Int()
+	statusAnnotate  = statusCommand.Flag("annotate", "Show up to this many names of failed tests for each job").Default("0").Int()
+	statusAssert    = statusCommand.Flag("assert", "Fail unless the number of jobs by result satisfy this, e.g. 'failure==0 && unstable<=2'").String()
+	statusQuiet     = statusCommand.Flag("quiet", "Only print jobs with problems, nothing if all jobs are fine").Short('q').Bool()
+	statusNotify    = statusCommand.Flag("notify-webhook", "Post a summary of failing jobs to this (Slack compatible) webhook URL").String()
+	statusUntil     = statusCommand.Flag("watch-until", "Query the status every --poll-interval until all jobs succeeded, a job failed or no job is running").Enum("success", "failure", "complete")
+	statusCacheTTL  = statusCommand.Flag("result-cache-ttl", "Reuse job statuses from previous runs which are younger than this (e.g. 10s)").Duration()
+	statusRefresh   = statusCommand.Flag("refresh", "Query all jobs even if their status is cached").Bool()
+	statusCause     = statusCommand.Flag("cause", "Only print jobs whose last build was started this way").Enum("timer", "user", "scm", "upstream", "remote", "indexing", "other")
+	statusCauseUser = statusCommand.Flag("cause-user", "Only print jobs whose last build was started by this user ID").String()
+	statusDiff      = statusCommand.Flag("watch-diff", "With --repeat or --watch-until, only print jobs whose result changed since the previous query").Bool()
+	statusSort      = statusCommand.Flag("sort", "Order the jobs by name or by the duration of their last build, longest first").Enum("name", "duration")
+	statusNameWidth = statusCommand.Flag("max-name-width", "Shorten job names longer than this in the middle in the text output, 0 for no limit").Default("0").Int()
+	statusUnknown   = statusCommand.Flag("fail-on-unknown", "Exit with 7 if the result of any job couldn't be determined, independent of --assert").Bool()
+	statusStrip     = statusCommand.Flag("strip-prefix", "Remove this prefix from the job names in the text output, or auto for their longest common prefix").String()
+	statusArchive   = statusCommand.Flag("archive-logs", "Save the console output of failed jobs to this directory").String()
+	statusBuilding  = statusCommand.Flag("only-building", "Only print jobs with a running build and how long it has been running").Bool()
 
-	buildCommand  = kingpin.Command("build", "Trigger build for all matching jobs")
-	buildRegexArg = buildCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	buildCommand   = kingpin.Command("build", "Trigger build for all matching jobs")
+	buildRegexArg  = buildCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	buildWait      = buildCommand.Flag("wait", "Wait for the triggered builds to finish").Short('w').Bool()
+	buildNode      = buildCommand.Flag("node", "Run the builds on nodes with this label, skipping jobs without a node or label parameter").String()
+	buildParams    = buildCommand.Flag("param", "Build parameter in NAME=VALUE format (repeatable)").Short('p').StringMap()
+	buildParamFile = buildCommand.Flag("param-file", "JSON or YAML file with build parameters, --param takes precedence").String()
 
 	logsCommand = kingpin.Command("logs", "Show the logs of a job")
-	logsJobArg  = logsCommand.Arg("job", "The name of the job to get logs for").Required().String()
+	logsJobArg  = logsCommand.Arg("job", "The name of the job to get logs for (a regular expression with --follow)").Required().String()
+	logsFollow  = logsCommand.Flag("follow", "Follow the logs of all matching jobs until their builds finish").Short('f').Bool()
+	logsBuild   = logsCommand.Flag("build", "The build number or a selector like lastSuccessful, lastStable or lastFailed").Default("last").String()
+	logsMaxAge  = logsCommand.Flag("max-age", "Fail if the last build is older than this (e.g. 1h)").Duration()
+	logsMinion  = logsCommand.Flag("minion", "Only show the failed salt states of this minion (with --salt)").String()
+	logsStates  = logsCommand.Flag("salt-max-states", "Only show the first N failed salt states (with --salt), 0 shows all").Default("0").Int()
+	logsDesc    = logsCommand.Flag("description", "Show the newest build with a description matching this regular expression").String()
+	logsResume  = logsCommand.Flag("resume", "Continue an interrupted --follow where it stopped instead of from the beginning").Bool()
+	logsStrip   = logsCommand.Flag("strip-ansi", "Remove ANSI escape sequences like colors from the console output").Bool()
+	logsOutput  = logsCommand.Flag("output", "Output format (text or ndjson with one JSON object per line), defaults to the output of the config file or text").Short('o').Enum("text", "ndjson")
+	logsOpen    = logsCommand.Flag("open", "Open the build page in the browser after printing the logs").Bool()
+	logsArchive = logsCommand.Flag("archive-logs", "Save the full console output to this directory").String()
+	logsMaxSize = logsCommand.Flag("max-output-bytes", "Only show the last N bytes of the console output, 0 shows everything (defaults to 1 MiB on a terminal)").Default(defaultMaxOutputBytes()).Int64()
+
+	setDescriptionCommand = kingpin.Command("set-description", "Set the description of a build, e.g. to annotate it with deployment info")
+	setDescriptionJobArg  = setDescriptionCommand.Arg("job", "The name of the job").Required().String()
+	setDescriptionTextArg = setDescriptionCommand.Arg("description", "The description, may contain HTML if Jenkins allows it").Required().String()
+	setDescriptionBuild   = setDescriptionCommand.Flag("build", "The build number or a selector like lastSuccessful, lastStable or lastFailed").Default("last").String()
+
+	describeCommand = kingpin.Command("describe", "Show whether a job is enabled or queued, its schedule with the next build and its last build")
+	describeJobArg  = describeCommand.Arg("job", "The name of the job").Required().String()
 
 	diffCommand   = kingpin.Command("diff", "Print a diff between two builds of a job")
 	diffJobArg    = diffCommand.Arg("job", "The name of the job to get the diff for").Required().String()
-	diffBuild1Arg = diffCommand.Arg("build1", "First build").Required().Int64()
-	diffBuild2Arg = diffCommand.Arg("build2", "Second build").Required().Int64()
+	diffBuild1Arg = diffCommand.Arg("build1", "First build").Int64()
+	diffBuild2Arg = diffCommand.Arg("build2", "Second build").Int64()
+	diffSince     = diffCommand.Flag("since-build", "Diff this build against the last build instead").Int64()
 
-	queueCommand  = kingpin.Command("queue", "Show the queue of all matching jobs")
-	queueRegexArg = queueCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	queueCommand   = kingpin.Command("queue", "Show the queue of all matching jobs")
+	queueRegexArg  = queueCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	queueHistogram = queueCommand.Flag("histogram", "Print how long the matching items have been waiting, e.g. <1m, 1-5m, 5-30m and >30m").Bool()
 
-	nodesCommand = kingpin.Command("nodes", "Show the status of all Jenkins nodes")
+	nodesCommand          = kingpin.Command("nodes", "Show the status of all Jenkins nodes")
+	nodesTimeout          = nodesCommand.Flag("timeout-per-node", "Report a node as unknown if polling it takes longer than this (0 to disable)").Default("10s").Duration()
+	nodesQuiet            = nodesCommand.Flag("quiet", "Only print nodes which are not online").Short('q').Bool()
+	nodesByLabel          = nodesCommand.Flag("group-by-label", "Group the nodes by their primary label with the number of online and offline nodes per label").Bool()
+	nodesListCommand      = nodesCommand.Command("list", "Show the status of all Jenkins nodes").Default()
+	nodesReconnectCommand = nodesCommand.Command("reconnect", "Print why a node is offline and relaunch its agent")
+	nodesReconnectNameArg = nodesReconnectCommand.Arg("name", "The name of the node").Required().String()
+	nodesReconnectWait    = nodesReconnectCommand.Flag("wait", "Time to wait for the agent to come back online (0 to not wait)").Default("1m").Duration()
 
 	openCommand  = kingpin.Command("open", "Open a job in the browser")
 	openRegexArg = openCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	openFailing  = openCommand.Flag("failing", "Only open jobs whose last build failed").Bool()
+	openPrint    = openCommand.Flag("print", "Print the URLs instead of opening them").Bool()
+
+	statsCommand  = kingpin.Command("stats", "Show the number of jobs by result, nodes by state and the queue length")
+	statsRegexArg = statsCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	statsOutput   = statsCommand.Flag("output", "Output format (text or json), defaults to the output of the config file or text").Short('o').Enum("text", "json")
+
+	stagesCommand = kingpin.Command("stages", "Show the stages of a Pipeline job's build")
+	stagesJobArg  = stagesCommand.Arg("job", "The name of the Pipeline job").Required().String()
+	stagesBuild   = stagesCommand.Flag("build", "The build number, defaults to the last build").Int64()
+
+	scanCommand = kingpin.Command("scan", "Trigger branch indexing for a multibranch pipeline job")
+	scanJobArg  = scanCommand.Arg("job", "The name of the multibranch job to scan").Required().String()
+
+	historyCommand        = kingpin.Command("history", "Show locally recorded history")
+	historyChangesCommand = historyCommand.Command("changes", "Show when jobs changed their result, as recorded by status")
+	historyChangesJobArg  = historyChangesCommand.Arg("job", "The regular expression to match for the job names").Default(".*").String()
+	historyBuildsCommand  = historyCommand.Command("builds", "List the builds of a job")
+	historyBuildsJobArg   = historyBuildsCommand.Arg("job", "The name of the job").Required().String()
+	historyBuildsSince    = historyBuildsCommand.Flag("since-build", "Only list builds newer than this build number or selector, e.g. lastSuccessful").String()
+
+	buildableCommand     = kingpin.Command("buildable", "List the matching jobs you are allowed to build")
+	buildableRegexArg    = buildableCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	buildableConcurrency = buildableCommand.Flag("concurrency", "Maximum number of jobs to check at the same time, 0 for no limit").Default("4").Int()
+
+	matchCommand  = kingpin.Command("match", "List the names of all matching jobs without doing anything else")
+	matchRegexArg = matchCommand.Arg("regex", "The regular expression to match for the job names").Required().String()
+	matchType     = matchCommand.Flag("type", "Only list jobs, only folders (including multibranch projects) or all").Default("all").Enum("job", "folder", "all")
+
+	rawCommand = kingpin.Command("raw", "Send an authenticated request to any Jenkins API endpoint and print the response")
+	rawPathArg = rawCommand.Arg("path", "The path of the endpoint, e.g. /pluginManager/api/json").Required().String()
+	rawMethod  = rawCommand.Flag("method", "The HTTP method").Short('X').Default("GET").String()
+	rawData    = rawCommand.Flag("data", "The request body").Short('d').String()
+	rawHeaders = rawCommand.Flag("header", "Additional header in \"Name: value\" format (repeatable)").Short('H').Strings()
+
+	artifactsCommand     = kingpin.Command("artifacts", "Download the artifacts of the last build of all matching jobs")
+	artifactsRegexArg    = artifactsCommand.Arg("regex", "The regular expression to match for the job names").Required().String()
+	artifactsDir         = artifactsCommand.Flag("dir", "Directory to save the artifacts to, in a subdirectory per build").Default(".").String()
+	artifactsBuild       = artifactsCommand.Flag("build", "The build number or a selector like lastSuccessful, lastStable or lastFailed").Default("last").String()
+	artifactsConcurrency = artifactsCommand.Flag("max-concurrent-downloads", "Maximum number of parallel downloads, 0 for no limit").Default("4").Int()
+
+	retryFailedCommand     = kingpin.Command("retry-failed", "Trigger all matching jobs whose last build failed again, with the same parameters")
+	retryFailedRegexArg    = retryFailedCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	retryFailedConcurrency = retryFailedCommand.Flag("concurrency", "Maximum number of builds to trigger at the same time, 0 for no limit").Default("4").Int()
+	retryFailedYes         = retryFailedCommand.Flag("yes", "Confirm triggering the builds").Bool()
+
+	flakyCommand  = kingpin.Command("flaky", "Show how often the results of the recent builds of all matching jobs flipped")
+	flakyRegexArg = flakyCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	flakySample   = flakyCommand.Flag("sample", "Number of recent builds to inspect per job").Default("20").Int()
+
+	topCommand  = kingpin.Command("top", "Show the running builds of all matching jobs, longest running first")
+	topRegexArg = topCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+
+	killStuckCommand  = kingpin.Command("kill-stuck", "Abort running builds which take much longer than estimated")
+	killStuckRegexArg = killStuckCommand.Arg("regex", "The regular expression to match for the job names").Default(".*").String()
+	killStuckFactor   = killStuckCommand.Flag("factor", "Abort builds running this many times longer than their estimated duration").Default("2").Float64()
+	killStuckDryRun   = killStuckCommand.Flag("dry-run", "Only print which builds would be aborted").Bool()
+
+	safeRestartCommand = kingpin.Command("safe-restart", "Restart Jenkins once all running builds are finished")
+	safeRestartYes     = safeRestartCommand.Flag("yes", "Confirm the restart").Bool()
+
+	quietDownCommand       = kingpin.Command("quiet-down", "Stop Jenkins from starting new builds, e.g. to prepare a shutdown")
+	cancelQuietDownCommand = kingpin.Command("cancel-quiet-down", "Let Jenkins start new builds again")
+
+	validateConfigCommand = kingpin.Command("validate-config", "Check the config file and its profiles for problems")
+	validateConfigPing    = validateConfigCommand.Flag("ping", "Connect to the instance of each profile to verify its credentials").Bool()
+
+	whoamiCommand = kingpin.Command("whoami", "Show the authenticated user to validate connectivity and credentials")
 
 	verbose = kingpin.Flag("verbose", "Verbose mode. Print full job output").Short('v').Bool()
 
+	profileName = kingpin.Flag("profile", "Use the Jenkins instance of this profile in ~/.riffraff/config.json").Envar("RIFFRAFF_PROFILE").String()
+	envName     = kingpin.Flag("env", "Shorthand for --profile").Envar("RIFFRAFF_ENV").String()
+
+	cookies           = kingpin.Flag("cookie", "Cookie to send to Jenkins, e.g. a SSO session (NAME=VALUE, repeatable)").Strings()
+	cookieFile        = kingpin.Flag("cookie-file", "Load cookies from a file in Netscape cookies.txt format").ExistingFile()
+	proxy             = kingpin.Flag("proxy", "HTTP or SOCKS5 proxy URL, overrides HTTP_PROXY and HTTPS_PROXY").String()
+	maxIdleConns      = kingpin.Flag("max-idle-conns", "Number of connections to Jenkins kept open for reuse").Default("100").Hidden().Int()
+	idleTimeout       = kingpin.Flag("idle-conn-timeout", "Close connections which weren't reused for this long").Default("90s").Hidden().Duration()
+	http2             = kingpin.Flag("http2", "Use HTTP/2 if Jenkins supports it, --no-http2 for proxies which break it").Default("true").Hidden().Bool()
+	httpCache         = kingpin.Flag("http-cache", "Revalidate repeated API requests with ETag and Last-Modified instead of fetching them again, --no-http-cache to disable").Default("true").Hidden().Bool()
+	credentialCommand = kingpin.Flag("credential-command", "Shell command printing the Jenkins password or token, overrides JENKINS_PW").Envar("RIFFRAFF_CREDENTIAL_COMMAND").String()
+
+	maxRetries   = kingpin.Flag("max-retries-total", "Maximum number of retries of failed polls across all jobs, -1 for no limit").Default("-1").Int()
+	pollInterval = kingpin.Flag("poll-interval", "Time to wait between polls when following logs, waiting for builds or repeating the status (at least 1s)").Default("2s").Duration()
+	triggerRate  = kingpin.Flag("trigger-rate", "Maximum number of builds triggered per second by build and retry-failed, e.g. 0.5, 0 for no limit").Default("0").Float64()
+
+	theme = kingpin.Flag("theme", "Color theme for the markers (dark, light or mono), defaults to the theme of the config file or dark").Envar("RIFFRAFF_THEME").Enum("dark", "light", "mono")
+
+	errorOnEmpty   = kingpin.Flag("error-on-empty", "Fail if the regular expression matches no jobs").Bool()
+	exclude        = kingpin.Flag("exclude", "Exclude jobs matching this regular expression (repeatable)").Strings()
+	pageSize       = kingpin.Flag("page-size", "Number of jobs to list per request, 0 lists all jobs at once").Default("500").Int()
+	print0         = kingpin.Flag("print0", "Separate job names and URLs printed by match, open --print and status --fields with NUL bytes for xargs -0").Bool()
+	null           = kingpin.Flag("null", "Same as --print0").Hidden().Bool()
+	confirmAt      = kingpin.Flag("confirm-threshold", "Make kill-stuck and retry-failed ask to type the number of jobs if at least this many match, 0 never asks").Default("10").Int()
+	glob           = kingpin.Flag("glob", "Interpret job patterns as shell globs like 'deploy-*' instead of regular expressions").Bool()
+	resolveAliases = kingpin.Flag("resolve-aliases", "Expand job aliases from the config file before matching, --no-resolve-aliases to use them as patterns").Default("true").Bool()
+
 	// TODO: Replace this with a custom formatter or so
 	salt = kingpin.Flag("salt", "Show failed salt states").Bool()
 )
 
 func main() {
+	kingpin.UsageTemplate(usageTemplate())
+	command := kingpin.Parse()
+	job.Settings.ErrorOnEmpty = *errorOnEmpty
+	job.Settings.Exclude = *exclude
+	job.Settings.PageSize = *pageSize
+	job.Settings.Glob = *glob
+	commands.SetNullDelimited(*print0 || *null)
+	commands.SetPollInterval(*pollInterval)
+	commands.SetMaxRetries(*maxRetries)
+	commands.SetTriggerRate(*triggerRate)
+	commands.SetConfirmThreshold(*confirmAt)
+
 	jenkinsURL := os.Getenv("JENKINS_URL")
 	jenkinsUser := os.Getenv("JENKINS_USER")
 	jenkinsPw := os.Getenv("JENKINS_PW")
 
+	httpOptions := clientOptions{
+		cookies:      *cookies,
+		cookieFile:   *cookieFile,
+		proxy:        *proxy,
+		maxIdleConns: *maxIdleConns,
+		idleTimeout:  *idleTimeout,
+		http2:        *http2,
+		cache:        *httpCache,
+	}
+
+	cfg, err := loadConfig()
+	commands.SetTheme(cfg.colorTheme(*theme))
+	// Checking the config must work without a Jenkins connection and with an invalid config
+	if command == "validate-config" {
+		if err == nil {
+			err = validateConfig(cfg, *validateConfigPing, httpOptions)
+		}
+		if err != nil {
+			log.Print(err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Cannot load config: %v", err)
+	}
+	// A selected profile takes precedence over the environment variables
+	if len(*profileName) > 0 || len(*envName) > 0 {
+		p, err := selectProfile(cfg, *profileName, *envName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		jenkinsURL, jenkinsUser = p.URL, p.User
+		job.Settings.JobPrefix = p.JobPrefix
+		if len(*credentialCommand) == 0 {
+			*credentialCommand = p.CredentialCommand
+		}
+	}
+
+	if *resolveAliases {
+		expandAliases(cfg)
+	}
+
 	if len(jenkinsURL) == 0 {
 		log.Fatal("Please set JENKINS_URL")
 	}
-	if len(jenkinsUser) == 0 {
+	if len(*credentialCommand) > 0 {
+		secret, err := runCredentialCommand(*credentialCommand)
+		if err != nil {
+			log.Fatalf("Cannot get credentials: %v", err)
+		}
+		jenkinsPw = secret
+	}
+	// With a session cookie, credentials are optional
+	useCookies := len(*cookies) > 0 || len(*cookieFile) > 0
+	if len(jenkinsUser) == 0 && !useCookies {
 		log.Fatal("Please set JENKINS_USER")
 	}
 
-	jenkins := gojenkins.CreateJenkins(nil, jenkinsURL, jenkinsUser, jenkinsPw)
+	client, err := newHTTPClient(jenkinsURL, httpOptions)
+	if err != nil {
+		log.Fatalf("Cannot create HTTP client: %v", err)
+	}
+
+	var auth []interface{}
+	if len(jenkinsUser) > 0 {
+		auth = []interface{}{jenkinsUser, jenkinsPw}
+	}
+	jenkins := gojenkins.CreateJenkins(client, jenkinsURL, auth...)
 	if jenkins == nil {
 		log.Fatal("Cannot instantiate Jenkins connection: null pointer return")
 	}
-	jenkins, err := jenkins.Init()
+	jenkins, err = jenkins.Init()
+	if err != nil {
+		log.Printf("Cannot connect to Jenkins at %v: %v\nPlease check JENKINS_URL and your network connection", jenkinsURL, err)
+		os.Exit(exitCode(&commands.ConnectionError{Err: err}))
+	}
 
+	// Init() succeeds even if the credentials are rejected,
+	// so check the status code of the root page explicitly
+	status, err := authStatus(jenkins)
 	if err != nil {
-		log.Fatalf("Cannot authenticate: %v", err)
+		log.Printf("Cannot connect to Jenkins at %v: %v\nPlease check JENKINS_URL and your network connection", jenkinsURL, err)
+		os.Exit(exitCode(&commands.ConnectionError{Err: err}))
+	}
+	if status == 401 || status == 403 {
+		log.Printf("Cannot authenticate as %v at %v (HTTP %v)\nPlease check JENKINS_USER and JENKINS_PW", jenkinsUser, jenkinsURL, status)
+		os.Exit(exitCode(&commands.AuthError{Err: fmt.Errorf("HTTP %v", status)}))
 	}
 
 	// TODO: Replace with a plugin-based system
 	// e.g. https://github.com/mitchellh/cli
-	switch kingpin.Parse() {
+	switch command {
 	case "status":
-		err = commands.NewStatus(jenkins, *statusRegexArg).Exec()
+		err = commands.NewStatus(jenkins, *statusRegexArg, commands.StatusOptions{
+			Output:          cfg.outputFormat(*statusOutput, "text", "json", "compact", "summary"),
+			Pretty:          *statusPretty,
+			IncludeDisabled: *statusDisabled,
+			Fields:          splitList(*statusFields),
+			Repeat:          *statusRepeat,
+			NotifyWebhook:   *statusNotify,
+			Annotate:        *statusAnnotate,
+			Assert:          *statusAssert,
+			Quiet:           *statusQuiet,
+			WatchUntil:      *statusUntil,
+			CacheTTL:        *statusCacheTTL,
+			Refresh:         *statusRefresh,
+			OnlyBuilding:    *statusBuilding,
+			Cause:           *statusCause,
+			CauseUser:       *statusCauseUser,
+			ArchiveLogs:     *statusArchive,
+			Sort:            *statusSort,
+			WatchDiff:       *statusDiff,
+			StripPrefix:     *statusStrip,
+			MaxNameWidth:    *statusNameWidth,
+			FailOnUnknown:   *statusUnknown,
+		}).Exec()
+	case "set-description":
+		err = commands.NewSetDescription(jenkins, *setDescriptionJobArg, *setDescriptionBuild, *setDescriptionTextArg).Exec()
+	case "describe":
+		err = commands.NewDescribe(jenkins, *describeJobArg).Exec()
 	case "diff":
-		err = commands.NewDiff(jenkins, *diffJobArg, *diffBuild1Arg, *diffBuild2Arg).Exec()
+		err = commands.NewDiff(jenkins, *diffJobArg, *diffBuild1Arg, *diffBuild2Arg, *diffSince).Exec()
 	case "build":
-		err = commands.NewBuild(jenkins, *buildRegexArg).Exec()
+		var params map[string]string
+		if params, err = buildParameters(*buildParamFile, *buildParams); err == nil {
+			err = commands.NewBuild(jenkins, *buildRegexArg, commands.BuildOptions{
+				Wait:   *buildWait,
+				Node:   *buildNode,
+				Params: params,
+			}).Exec()
+		}
 	case "logs":
-		err = commands.NewLogs(jenkins, *logsJobArg, *salt).Exec()
+		if *logsFollow {
+			err = commands.NewFollow(jenkins, *logsJobArg, cfg.outputFormat(*logsOutput, "text", "ndjson"), *logsResume, *logsStrip).Exec()
+		} else {
+			err = commands.NewLogs(commands.Server{Jenkins: jenkins}, *logsJobArg, commands.LogsOptions{
+				Salt:          *salt,
+				Minion:        *logsMinion,
+				SaltMaxStates: *logsStates,
+				MaxAge:        *logsMaxAge,
+				Build:         *logsBuild,
+				Description:   *logsDesc,
+				MaxBytes:      *logsMaxSize,
+				Output:        cfg.outputFormat(*logsOutput, "text", "ndjson"),
+				Open:          *logsOpen,
+				ArchiveLogs:   *logsArchive,
+				StripANSI:     *logsStrip,
+			}).Exec()
+		}
 	case "queue":
-		err = commands.NewQueue(jenkins, *queueRegexArg, *verbose, *salt).Exec()
-	case "nodes":
-		err = commands.NewNodes(jenkins).Exec()
+		err = commands.NewQueue(jenkins, *queueRegexArg, *verbose, *salt, *queueHistogram).Exec()
+	case "nodes list":
+		err = commands.NewNodes(jenkins, *nodesTimeout, *nodesQuiet, *nodesByLabel).Exec()
+	case "nodes reconnect":
+		err = commands.NewReconnect(jenkins, *nodesReconnectNameArg, *nodesReconnectWait).Exec()
 	case "open":
-		err = commands.NewOpen(jenkins, *openRegexArg).Exec()
+		err = commands.NewOpen(jenkins, *openRegexArg, *openFailing, *openPrint).Exec()
+	case "stats":
+		err = commands.NewStats(jenkins, *statsRegexArg, cfg.outputFormat(*statsOutput, "text", "json")).Exec()
+	case "stages":
+		err = commands.NewStages(jenkins, *stagesJobArg, *stagesBuild).Exec()
+	case "scan":
+		err = commands.NewScan(jenkins, *scanJobArg).Exec()
+	case "history changes":
+		err = commands.NewChanges(jenkins, *historyChangesJobArg).Exec()
+	case "history builds":
+		err = commands.NewBuilds(jenkins, *historyBuildsJobArg, *historyBuildsSince).Exec()
+	case "buildable":
+		err = commands.NewBuildable(jenkins, *buildableRegexArg, *buildableConcurrency).Exec()
+	case "match":
+		err = commands.NewMatch(jenkins, *matchRegexArg, *matchType).Exec()
+	case "raw":
+		err = commands.NewRaw(jenkins, *rawMethod, *rawPathArg, *rawData, *rawHeaders).Exec()
+	case "artifacts":
+		err = commands.NewArtifacts(jenkins, *artifactsRegexArg, *artifactsDir, *artifactsConcurrency, *artifactsBuild).Exec()
+	case "retry-failed":
+		err = commands.NewRetryFailed(jenkins, *retryFailedRegexArg, *retryFailedConcurrency, *retryFailedYes).Exec()
+	case "flaky":
+		err = commands.NewFlaky(jenkins, *flakyRegexArg, *flakySample).Exec()
+	case "top":
+		err = commands.NewTop(jenkins, *topRegexArg).Exec()
+	case "kill-stuck":
+		err = commands.NewKillStuck(jenkins, *killStuckRegexArg, *killStuckFactor, *killStuckDryRun).Exec()
+	case "safe-restart":
+		err = commands.NewAdmin(jenkins, command, *safeRestartYes).Exec()
+	case "quiet-down", "cancel-quiet-down":
+		err = commands.NewAdmin(jenkins, command, false).Exec()
+	case "whoami":
+		err = commands.NewWhoAmI(jenkins).Exec()
 	default:
 		kingpin.Usage()
 	}
 
 	if err != nil {
-		log.Fatalf("Cannot execute command: %v", err)
+		err = commands.Classify(err)
+		log.Printf("Cannot execute command: %v", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode tells scripts which kind of error made a command fail
+func exitCode(err error) int {
+	var (
+		authErr       *commands.AuthError
+		permissionErr *commands.PermissionError
+		notFoundErr   *commands.NotFoundError
+		connectionErr *commands.ConnectionError
+		unknownErr    *commands.UnknownResultError
+	)
+	switch {
+	case errors.As(err, &authErr):
+		return 3
+	case errors.As(err, &permissionErr):
+		return 4
+	case errors.As(err, &notFoundErr):
+		return 5
+	case errors.As(err, &connectionErr):
+		return 6
+	case errors.As(err, &unknownErr):
+		return 7
+	}
+	return 1
+}
+
+// expandAliases replaces the job arguments which are aliases from the config file
+// with the job name, or with a pattern matching exactly this job
+func expandAliases(cfg config) {
+	for _, arg := range []*string{setDescriptionJobArg, describeJobArg, diffJobArg, stagesJobArg, scanJobArg, historyBuildsJobArg} {
+		*arg = cfg.jobName(*arg)
+	}
+	for _, arg := range []*string{statusRegexArg, buildRegexArg, queueRegexArg, openRegexArg, statsRegexArg, historyChangesJobArg,
+		matchRegexArg, buildableRegexArg, artifactsRegexArg, retryFailedRegexArg, flakyRegexArg, topRegexArg, killStuckRegexArg} {
+		*arg = cfg.jobPattern(*arg)
+	}
+	// With --follow, the job is a pattern as well
+	if *logsFollow {
+		*logsJobArg = cfg.jobPattern(*logsJobArg)
+	} else {
+		*logsJobArg = cfg.jobName(*logsJobArg)
+	}
+}
+
+// splitList splits a comma separated list and drops empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// defaultMaxOutputBytes caps logs at 1 MiB on a terminal
+// but not when the output is redirected to a file or a pipe
+func defaultMaxOutputBytes() string {
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return "1048576"
 	}
+	return "0"
 }
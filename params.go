@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readParamFile reads build parameters from a JSON object or a flat YAML mapping of "NAME: value" lines
+func readParamFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	params := map[string]string{}
+	if filepath.Ext(path) == ".json" {
+		var values map[string]interface{}
+		decoder := json.NewDecoder(file)
+		// Numbers would be formatted as floats otherwise, e.g. 1000000 as "1e+06"
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("invalid parameter file %v: %v", path, err)
+		}
+		for name, value := range values {
+			switch value := value.(type) {
+			case string:
+				params[name] = value
+			case json.Number, bool:
+				params[name] = fmt.Sprint(value)
+			default:
+				return nil, fmt.Errorf("invalid parameter %v in %v: expected a string, number or boolean", name, path)
+			}
+		}
+		return params, nil
+	}
+
+	// Only flat mappings are supported, anything else is rejected instead of being misread
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if line != trimmed {
+			return nil, fmt.Errorf("invalid line in parameter file %v: %q, nested values aren't supported", path, line)
+		}
+		parts := strings.SplitN(line, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.ContainsAny(name[:1], "-?\"'[{") {
+			return nil, fmt.Errorf("invalid line in parameter file %v: %q, expected NAME: value", path, line)
+		}
+		value, err := yamlScalar(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %v in parameter file %v: %v", name, path, err)
+		}
+		params[name] = value
+	}
+	return params, scanner.Err()
+}
+
+// yamlScalar parses a plain, single or double quoted YAML value with an optional comment
+func yamlScalar(value string) (string, error) {
+	switch {
+	case value == "":
+		return "", fmt.Errorf(`missing value, nested values aren't supported, use "" for an empty value`)
+	case value[0] == '"' || value[0] == '\'':
+		end := closingQuote(value)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %v", value)
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after string", rest)
+		}
+		if value[0] == '\'' {
+			return strings.Replace(value[1:end], "''", "'", -1), nil
+		}
+		return strconv.Unquote(value[:end+1])
+	case strings.ContainsAny(value[:1], "[{|>&*!%@`"):
+		return "", fmt.Errorf("%v isn't supported, only plain and quoted strings are", value)
+	}
+	// A comment starts with a # after whitespace
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	if i := strings.Index(value, "\t#"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
+
+// closingQuote returns the index of the quote which ends a quoted YAML string, or -1
+func closingQuote(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote && quote == '\'' && i+1 < len(value) && value[i+1] == '\'':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// buildParameters merges the parameters of a parameter file with the ones from the command line,
+// which take precedence
+func buildParameters(paramFile string, flags map[string]string) (map[string]string, error) {
+	if paramFile == "" {
+		return flags, nil
+	}
+	params, err := readParamFile(paramFile)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range flags {
+		params[name] = value
+	}
+	return params, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadParamFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "json",
+			file:    "params.json",
+			content: `{"SIZE": 1000000, "RATIO": 0.5, "DRY_RUN": true, "VERSION": "1.2.3"}`,
+			want:    map[string]string{"SIZE": "1000000", "RATIO": "0.5", "DRY_RUN": "true", "VERSION": "1.2.3"},
+		},
+		{
+			name:    "json with nested value",
+			file:    "params.json",
+			content: `{"HOSTS": ["a", "b"]}`,
+			wantErr: "invalid parameter HOSTS",
+		},
+		{
+			name:    "json with null",
+			file:    "params.json",
+			content: `{"VERSION": null}`,
+			wantErr: "invalid parameter VERSION",
+		},
+		{
+			name: "yaml",
+			file: "params.yaml",
+			content: "---\n# deployment\nVERSION: 1.2.3\nURL: https://example.com:8443/x\n" +
+				"SIZE: 1000000 # bytes\nGREETING: \"hello # world\"  # comment\nNAME: 'it''s'\n" +
+				"ESCAPED: \"a\\tb\"\nEMPTY: \"\"\n",
+			want: map[string]string{
+				"VERSION": "1.2.3", "URL": "https://example.com:8443/x", "SIZE": "1000000",
+				"GREETING": "hello # world", "NAME": "it's", "ESCAPED": "a\tb", "EMPTY": "",
+			},
+		},
+		{
+			name:    "yaml with nested mapping",
+			file:    "params.yaml",
+			content: "DEPLOY:\n  VERSION: 1.2.3\n",
+			wantErr: "invalid value of DEPLOY",
+		},
+		{
+			name:    "yaml with indented line",
+			file:    "params.yaml",
+			content: "  VERSION: 1.2.3\n",
+			wantErr: "nested values aren't supported",
+		},
+		{
+			name:    "yaml with list",
+			file:    "params.yml",
+			content: "- VERSION\n",
+			wantErr: "expected NAME: value",
+		},
+		{
+			name:    "yaml with flow sequence",
+			file:    "params.yml",
+			content: "HOSTS: [a, b]\n",
+			wantErr: "isn't supported",
+		},
+		{
+			name:    "yaml with block scalar",
+			file:    "params.yml",
+			content: "SCRIPT: |\n  echo hi\n",
+			wantErr: "isn't supported",
+		},
+		{
+			name:    "yaml with unterminated string",
+			file:    "params.yml",
+			content: "VERSION: \"1.2.3\n",
+			wantErr: "unterminated string",
+		},
+		{
+			name:    "yaml with text after string",
+			file:    "params.yml",
+			content: "VERSION: \"1.2\".3\n",
+			wantErr: "after string",
+		},
+		{
+			name:    "yaml without colon",
+			file:    "params.yml",
+			content: "VERSION 1.2.3\n",
+			wantErr: "expected NAME: value",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.file)
+			if err := ioutil.WriteFile(path, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readParamFile(path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+// examples are shown in the help of each command, e.g. riffraff help status
+var examples = map[string][]string{
+	"status": {
+		"riffraff status 'deploy-.*'",
+		"riffraff status -o json --pretty '^application-.*-unittests$'",
+		"riffraff status --quiet --assert 'failure==0' 'deploy-.*'",
+	},
+	"build": {
+		"riffraff build '^deploy-api$' --param VERSION=1.2.3",
+		"riffraff build --wait --node linux 'integration-.*'",
+	},
+	"logs": {
+		"riffraff logs my-job",
+		"riffraff logs my-job --build lastFailed",
+		"riffraff logs my-job --salt --minion web01",
+		"riffraff logs --follow '^deploy-.*'",
+	},
+	"describe":          {"riffraff describe my-job"},
+	"diff":              {"riffraff diff my-job 41 42", "riffraff diff my-job --since-build 100"},
+	"queue":             {"riffraff queue 'deploy-.*'", "riffraff --verbose queue"},
+	"nodes list":        {"riffraff nodes --quiet"},
+	"nodes reconnect":   {"riffraff nodes reconnect build-agent-3"},
+	"open":              {"riffraff open my-job", "riffraff open --failing 'deploy-.*'"},
+	"stats":             {"riffraff stats -o json 'deploy-.*'"},
+	"stages":            {"riffraff stages my-pipeline", "riffraff stages my-pipeline --build 42"},
+	"scan":              {"riffraff scan my-multibranch-pipeline"},
+	"history builds":    {"riffraff history builds my-job --since-build lastSuccessful"},
+	"history changes":   {"riffraff history changes '^application-api-unittests$'"},
+	"match":             {"riffraff match 'deploy-.*'"},
+	"raw":               {"riffraff raw /computer/api/json", "riffraff raw -X POST /job/my-job/disable"},
+	"artifacts":         {"riffraff artifacts '^release-.*' --build lastSuccessful --dir ./artifacts", "riffraff artifacts my-job --max-concurrent-downloads 1"},
+	"retry-failed":      {"riffraff retry-failed 'integration-.*'", "riffraff retry-failed --yes --concurrency 2 'integration-.*'"},
+	"flaky":             {"riffraff flaky 'integration-.*'", "riffraff flaky --sample 50 my-job"},
+	"top":               {"riffraff top", "riffraff top 'deploy-.*'"},
+	"kill-stuck":        {"riffraff kill-stuck --dry-run 'deploy-.*'", "riffraff kill-stuck --factor 3"},
+	"safe-restart":      {"riffraff safe-restart --yes"},
+	"quiet-down":        {"riffraff quiet-down"},
+	"cancel-quiet-down": {"riffraff cancel-quiet-down"},
+	"validate-config":   {"riffraff validate-config --ping"},
+	"set-description":   {"riffraff set-description deploy-api --build 42 'Deployed 1.2.3 to production'"},
+	"buildable":         {"riffraff buildable '^deploy-.*'"},
+	"whoami":            {"riffraff whoami"},
+}
+
+// usageTemplate extends kingpin's default usage template with the examples of the selected command
+func usageTemplate() string {
+	var commands []string
+	for command := range examples {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+
+	var template strings.Builder
+	template.WriteString(kingpin.DefaultUsageTemplate)
+	template.WriteString("{{with .Context.SelectedCommand}}")
+	for _, command := range commands {
+		fmt.Fprintf(&template, "{{if eq .FullCommand %q}}Examples:\n  %v\n\n{{end}}", command, strings.Join(examples[command], "\n  "))
+	}
+	template.WriteString("{{end}}")
+	return template.String()
+}